@@ -0,0 +1,174 @@
+// Package apperror defines the typed errors returned by repositories and services. The controllers
+// map them to HTTP responses from their kind, see controller.RespondError.
+package apperror
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+)
+
+// Kind classifies an error, the HTTP status of the response depending on it.
+type Kind string
+
+const (
+	KindNotFound     Kind = "not_found"
+	KindValidation   Kind = "validation"
+	KindConflict     Kind = "conflict"
+	KindForbidden    Kind = "forbidden"
+	KindUnauthorized Kind = "unauthorized"
+	KindTimeout      Kind = "timeout"
+	KindCanceled     Kind = "canceled"
+	KindUnsupported  Kind = "unsupported_media_type"
+	KindPrecondition Kind = "precondition_failed"
+	KindInternal     Kind = "internal"
+)
+
+// FieldError describes why the value of a field was rejected.
+type FieldError struct {
+	// Field is the JSON path of the field, e.g. "address.city".
+	Field string `json:"field"`
+	// Rule is the rule that failed, e.g. "required", and Param its parameter, e.g. "3" for "min=3".
+	Rule    string `json:"rule"`
+	Param   string `json:"param"`
+	Message string `json:"message"`
+	// translate returns the message in the language of a translator, see Error.LocalizedFields.
+	translate func(ut.Translator) string
+}
+
+// Error is a typed error. Code is a stable machine-readable identifier of the error, e.g.
+// "version_conflict", and Detail its human-readable description. Extensions carry additional
+// members of the error, such as the fields allowed by a rejected parameter.
+type Error struct {
+	Kind       Kind
+	Code       string
+	Detail     string
+	Fields     []FieldError
+	Extensions map[string]interface{}
+	Cause      error
+}
+
+func (e *Error) Error() string {
+	return e.Detail
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// With returns a copy of the error carrying the given extension member.
+func (e *Error) With(name string, value interface{}) *Error {
+	extended := *e
+	extended.Extensions = make(map[string]interface{}, len(e.Extensions)+1)
+	for key, existing := range e.Extensions {
+		extended.Extensions[key] = existing
+	}
+	extended.Extensions[name] = value
+	return &extended
+}
+
+// LocalizedFields returns the fields of the error with their messages translated by trans, when
+// they can be. See Translator.
+func (e *Error) LocalizedFields(trans ut.Translator) []FieldError {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	fields := make([]FieldError, len(e.Fields))
+	for i, field := range e.Fields {
+		fields[i] = field
+		if field.translate != nil {
+			fields[i].Message = field.translate(trans)
+		}
+	}
+	return fields
+}
+
+// Typed is implemented by errors of other types that describe themselves as an Error, such as
+// the errors carrying the value they reject.
+type Typed interface {
+	AppError() *Error
+}
+
+func New(kind Kind, code string, detail string) *Error {
+	return &Error{Kind: kind, Code: code, Detail: detail}
+}
+
+func NotFound(code string, detail string) *Error {
+	return New(KindNotFound, code, detail)
+}
+
+func Validation(code string, detail string, fields ...FieldError) *Error {
+	validation := New(KindValidation, code, detail)
+	validation.Fields = fields
+	return validation
+}
+
+func Conflict(code string, detail string) *Error {
+	return New(KindConflict, code, detail)
+}
+
+func Forbidden(code string, detail string) *Error {
+	return New(KindForbidden, code, detail)
+}
+
+func Unauthorized(code string, detail string) *Error {
+	return New(KindUnauthorized, code, detail)
+}
+
+// Wrap returns an error of the given kind caused by err, described by its message.
+func Wrap(kind Kind, code string, err error) *Error {
+	return &Error{Kind: kind, Code: code, Detail: err.Error(), Cause: err}
+}
+
+// Invalid returns the typed error of err when it has one, and a validation error caused by err
+// otherwise. It is meant for errors of the request itself, e.g. a body that cannot be decoded.
+func Invalid(err error) *Error {
+	if typed := typedOf(err); typed != nil {
+		return typed
+	}
+	return Wrap(KindValidation, "invalid_request", err)
+}
+
+// InvalidItem returns the validation error of item, as Invalid does, naming the invalid fields after
+// their JSON name whatever the tag name function of the validator that checked item.
+func InvalidItem(item any, err error) *Error {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		return fromValidation(validationErrors, reflect.TypeOf(item))
+	}
+	return Invalid(err)
+}
+
+// From returns the typed error of err. Errors without one are internal errors, whose detail does
+// not disclose their cause.
+func From(err error) *Error {
+	if typed := typedOf(err); typed != nil {
+		return typed
+	}
+	if errors.Is(err, context.Canceled) {
+		return &Error{Kind: KindCanceled, Code: "canceled", Detail: "the request was cancelled by the client", Cause: err}
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &Error{Kind: KindTimeout, Code: "timeout", Detail: "the request did not complete in time", Cause: err}
+	}
+	return &Error{Kind: KindInternal, Code: "internal_error", Detail: "an unexpected error occurred", Cause: err}
+}
+
+func typedOf(err error) *Error {
+	var typed *Error
+	if errors.As(err, &typed) {
+		return typed
+	}
+	var describing Typed
+	if errors.As(err, &describing) {
+		return describing.AppError()
+	}
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		return fromValidation(validationErrors, nil)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package apperror
+
+import (
+	"fmt"
+	"strings"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// schemaRules maps the JSON schema error types to the equivalent validator rules, for the errors of
+// both to be reported alike.
+var schemaRules = map[string]string{
+	"required":   "required",
+	"enum":       "oneof",
+	"const":      "eq",
+	"string_gte": "min",
+	"string_lte": "max",
+	"number_gte": "gte",
+	"number_gt":  "gt",
+	"number_lte": "lte",
+	"number_lt":  "lt",
+}
+
+// schemaParams names the detail of each JSON schema error type holding the parameter of its rule.
+var schemaParams = map[string]string{
+	"enum":       "allowed",
+	"const":      "allowed",
+	"string_gte": "min",
+	"string_lte": "max",
+	"number_gte": "min",
+	"number_gt":  "min",
+	"number_lte": "max",
+	"number_lt":  "max",
+	"format":     "format",
+	"pattern":    "pattern",
+}
+
+// FromSchema returns the validation error listing the errors of a JSON schema validation.
+func FromSchema(results []gojsonschema.ResultError) *Error {
+	initValidation()
+	fallback := universal.GetFallback()
+	fields := make([]FieldError, 0, len(results))
+	for _, result := range results {
+		field := FieldError{Field: schemaField(result), Rule: result.Type()}
+		if name, ok := schemaParams[result.Type()]; ok && result.Details()[name] != nil {
+			field.Param = fmt.Sprint(result.Details()[name])
+		}
+		translated := false
+		if rule, ok := schemaRules[result.Type()]; ok {
+			field.Rule, translated = rule, true
+		} else if result.Type() == "format" {
+			// the format is the rule of the validator, e.g. email
+			field.Rule, field.Param, translated = field.Param, "", true
+		}
+		field.translate = translateSchema(field, translated, result.Description())
+		field.Message = field.translate(fallback)
+		fields = append(fields, field)
+	}
+	return Validation("validation_failed", "the request has invalid fields", fields...)
+}
+
+// schemaField returns the JSON path of the field of a schema error. A missing property is
+// reported on its object, its path ends with the property.
+func schemaField(result gojsonschema.ResultError) string {
+	field := result.Field()
+	if field == gojsonschema.STRING_CONTEXT_ROOT {
+		field = ""
+	}
+	if property, ok := result.Details()["property"].(string); ok && result.Type() == "required" {
+		if field == "" {
+			return property
+		}
+		return field + "." + property
+	}
+	return field
+}
+
+// translateSchema translates the message of a schema error with the one of the equivalent
+// validator rule, when there is one, and keeps the description of the error otherwise.
+func translateSchema(field FieldError, translated bool, description string) func(ut.Translator) string {
+	return func(trans ut.Translator) string {
+		if !translated {
+			return description
+		}
+		name := field.Field[strings.LastIndex(field.Field, ".")+1:]
+		if message, err := trans.T(field.Rule, name, field.Param); err == nil {
+			return message
+		}
+		return description
+	}
+}
@@ -0,0 +1,223 @@
+package apperror
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/de"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	"github.com/go-playground/locales/fr"
+	"github.com/go-playground/locales/it"
+	"github.com/go-playground/locales/nl"
+	"github.com/go-playground/locales/pt"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	de_translations "github.com/go-playground/validator/v10/translations/de"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	es_translations "github.com/go-playground/validator/v10/translations/es"
+	fr_translations "github.com/go-playground/validator/v10/translations/fr"
+	it_translations "github.com/go-playground/validator/v10/translations/it"
+	nl_translations "github.com/go-playground/validator/v10/translations/nl"
+	pt_translations "github.com/go-playground/validator/v10/translations/pt"
+)
+
+// translation is a language in which the messages of field errors are available.
+type translation struct {
+	locale   locales.Translator
+	register func(*validator.Validate, ut.Translator) error
+}
+
+var translations = []translation{
+	{en.New(), en_translations.RegisterDefaultTranslations},
+	{de.New(), de_translations.RegisterDefaultTranslations},
+	{es.New(), es_translations.RegisterDefaultTranslations},
+	{fr.New(), fr_translations.RegisterDefaultTranslations},
+	{it.New(), it_translations.RegisterDefaultTranslations},
+	{nl.New(), nl_translations.RegisterDefaultTranslations},
+	{pt.New(), pt_translations.RegisterDefaultTranslations},
+}
+
+var (
+	validationOnce sync.Once
+	validate       *validator.Validate
+	universal      *ut.UniversalTranslator
+)
+
+func initValidation() {
+	validationOnce.Do(func() {
+		locales := make([]locales.Translator, 0, len(translations))
+		for _, translation := range translations {
+			locales = append(locales, translation.locale)
+		}
+		universal = ut.New(translations[0].locale, locales...)
+
+		validate = validator.New()
+		UseJSONNames(validate)
+		for _, translation := range translations {
+			trans, _ := universal.GetTranslator(translation.locale.Locale())
+			if err := translation.register(validate, trans); err != nil {
+				panic(err)
+			}
+		}
+	})
+}
+
+// Validator returns the validator to pass to the services. It names the fields after their JSON
+// name, and has the messages of its rules translated in every language of Translator. The errors
+// of other validators are translated as well for the rules not depending on the type of the field.
+func Validator() *validator.Validate {
+	initValidation()
+	return validate
+}
+
+// UseJSONNames makes validate name the fields after their JSON name, as Validator does. The errors of
+// the services report the JSON names whatever the validator, see InvalidItem.
+func UseJSONNames(validate *validator.Validate) {
+	validate.RegisterTagNameFunc(jsonName)
+}
+
+// Translator returns the translator of the language preferred by an Accept-Language header, e.g.
+// "fr-CH, fr;q=0.9, en;q=0.8", English when none of its languages is supported.
+func Translator(acceptLanguage string) ut.Translator {
+	initValidation()
+	trans, _ := universal.FindTranslator(acceptedLocales(acceptLanguage)...)
+	return trans
+}
+
+// acceptedLocales returns the locales of an Accept-Language header by decreasing preference, each
+// region-specific locale being followed by its language.
+func acceptedLocales(acceptLanguage string) []string {
+	type weighted struct {
+		locale  string
+		quality float64
+	}
+	var accepted []weighted
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				quality = parsed
+			}
+		}
+		if tag == "" || tag == "*" || quality <= 0 {
+			continue
+		}
+		accepted = append(accepted, weighted{locale: strings.ReplaceAll(tag, "-", "_"), quality: quality})
+	}
+	sort.SliceStable(accepted, func(i, j int) bool {
+		return accepted[i].quality > accepted[j].quality
+	})
+
+	locales := make([]string, 0, 2*len(accepted))
+	for _, locale := range accepted {
+		locales = append(locales, locale.locale)
+		if language, _, regional := strings.Cut(locale.locale, "_"); regional {
+			locales = append(locales, language)
+		}
+	}
+	return locales
+}
+
+// jsonName names a struct field after its JSON name.
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// fromValidation returns the validation error of validationErrors. When the type of the validated
+// item is given, the fields are named after their JSON name, found from their Go namespace.
+func fromValidation(validationErrors validator.ValidationErrors, itemType reflect.Type) *Error {
+	initValidation()
+	fallback := universal.GetFallback()
+	fields := make([]FieldError, 0, len(validationErrors))
+	for _, fieldError := range validationErrors {
+		path, name := fieldPath(fieldError.Namespace()), fieldError.Field()
+		if itemType != nil {
+			if jsonPath, ok := jsonFieldPath(itemType, fieldError.StructNamespace()); ok {
+				path = jsonPath
+				name = jsonPath[strings.LastIndex(jsonPath, ".")+1:]
+				name, _, _ = strings.Cut(name, "[")
+			}
+		}
+		field := FieldError{
+			Field:     path,
+			Rule:      fieldError.Tag(),
+			Param:     fieldError.Param(),
+			translate: translateValidation(fieldError, name),
+		}
+		field.Message = field.translate(fallback)
+		fields = append(fields, field)
+	}
+	validation := Validation("validation_failed", "the request has invalid fields", fields...)
+	validation.Cause = validationErrors
+	return validation
+}
+
+// fieldPath returns the path of a field from its namespace, e.g. "address.city" for
+// "Organization.address.city".
+func fieldPath(namespace string) string {
+	if _, path, nested := strings.Cut(namespace, "."); nested {
+		return path
+	}
+	return namespace
+}
+
+// jsonFieldPath returns the JSON path of a field from its Go namespace, e.g. "address.city" for
+// "SignUp.Address.City", and false when a field of the namespace is not one of itemType.
+func jsonFieldPath(itemType reflect.Type, structNamespace string) (string, bool) {
+	segments := strings.Split(structNamespace, ".")
+	path := make([]string, 0, len(segments))
+	for _, segment := range segments[1:] {
+		for itemType.Kind() == reflect.Ptr {
+			itemType = itemType.Elem()
+		}
+		if itemType.Kind() != reflect.Struct {
+			return "", false
+		}
+		fieldName, index, indexed := strings.Cut(segment, "[")
+		field, ok := itemType.FieldByName(fieldName)
+		if !ok {
+			return "", false
+		}
+		name := jsonName(field)
+		if name == "" {
+			name = field.Name
+		}
+		itemType = field.Type
+		if indexed {
+			name += "[" + index
+			for itemType.Kind() == reflect.Ptr {
+				itemType = itemType.Elem()
+			}
+			switch itemType.Kind() {
+			case reflect.Slice, reflect.Array, reflect.Map:
+				itemType = itemType.Elem()
+			}
+		}
+		path = append(path, name)
+	}
+	return strings.Join(path, "."), true
+}
+
+func translateValidation(fieldError validator.FieldError, name string) func(ut.Translator) string {
+	return func(trans ut.Translator) string {
+		if message := fieldError.Translate(trans); message != fieldError.Error() {
+			return message
+		}
+		// the error comes from another validator, the translator only knows the messages of its
+		// rules not depending on the type of the field
+		if message, err := trans.T(fieldError.Tag(), name, fieldError.Param()); err == nil {
+			return message
+		}
+		return fieldError.Error()
+	}
+}
@@ -1,3 +1,12 @@
 package constants
 
 const TokenKey = "github.com/go-oauth2/gin-server/access-token"
+
+// AdminScope is the token scope granting access to privileged operations such as purging entities.
+const AdminScope = "admin"
+
+// OrganizationHeader is the request header selecting the active organization of a user belonging to several.
+const OrganizationHeader = "X-Organization-Id"
+
+// RequestIdHeader carries the id of a request, generated when the client does not send one, and is recorded in the audit log.
+const RequestIdHeader = "X-Request-Id"
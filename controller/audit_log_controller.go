@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/roksky/bootstrap-api/constants"
+	"github.com/roksky/bootstrap-api/model"
+	"github.com/roksky/bootstrap-api/repository"
+	"github.com/roksky/bootstrap-api/service"
+)
+
+// AuditLogController exposes the audit log of every organization read only to administrators, e.g.
+// GET /audit-log?entityType=Organization&entityId=...
+type AuditLogController struct {
+	*CrudController[model.AuditLog, uuid.UUID, repository.AuditLogSearch]
+}
+
+func NewAuditLogController(service service.BaseService[model.AuditLog, uuid.UUID, repository.AuditLogSearch]) *AuditLogController {
+	controller := NewCrudController(service, "/audit-log", uuid.Parse, bindAuditLogSearch)
+	controller.EnableOnly(OpSearch, OpFindById, OpSortFields, OpStats)
+	return &AuditLogController{
+		CrudController: controller,
+	}
+}
+
+func (controller *AuditLogController) Handlers() []*HttpFunc {
+	handlers := controller.CrudController.Handlers()
+	for _, handler := range handlers {
+		handler.httpFunc = RequireScope(constants.AdminScope, withoutTenantScope(handler.httpFunc))
+	}
+	return handlers
+}
+
+// withoutTenantScope runs handler with the request context lifted out of the tenant scope, see
+// repository.WithoutTenantScope.
+func withoutTenantScope(handler gin.HandlerFunc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Request = ctx.Request.WithContext(repository.WithoutTenantScope(ctx.Request.Context()))
+		handler(ctx)
+	}
+}
+
+func bindAuditLogSearch(ctx *gin.Context) (*repository.AuditLogSearch, error) {
+	search, err := BindSearch[repository.AuditLogSearch](ctx)
+	if err != nil {
+		return nil, err
+	}
+	if search.OrderBy == "" {
+		search.OrderBy = "dateCreated:desc"
+	}
+	return search, nil
+}
@@ -1,10 +1,13 @@
 package controller
 
 import (
-	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-oauth2/oauth2/v4"
+	"github.com/roksky/bootstrap-api/apperror"
 	"github.com/roksky/bootstrap-api/constants"
 )
 
@@ -22,6 +25,7 @@ type HttpFunc struct {
 	method      HttpMethod
 	httpFunc    gin.HandlerFunc
 	urlTemplate string
+	timeout     time.Duration
 }
 
 func NewHttpFunc(method HttpMethod, urlTemplate string, httpFunc gin.HandlerFunc) *HttpFunc {
@@ -44,6 +48,17 @@ func (h *HttpFunc) GetUrlTemplate() string {
 	return h.urlTemplate
 }
 
+// WithTimeout sets the duration after which the context of the route requests is cancelled.
+func (h *HttpFunc) WithTimeout(timeout time.Duration) *HttpFunc {
+	h.timeout = timeout
+	return h
+}
+
+// GetTimeout returns the timeout of the route, zero to use the one of the router.
+func (h *HttpFunc) GetTimeout() time.Duration {
+	return h.timeout
+}
+
 type Controller interface {
 	GroupName() string
 	Handlers() []*HttpFunc
@@ -53,9 +68,34 @@ type Controller interface {
 func GetTokenInfo(ctx *gin.Context) (oauth2.TokenInfo, error) {
 	ti, exists := ctx.Get(constants.TokenKey)
 	if !exists {
-		return nil, errors.New("no token info found")
+		return nil, apperror.Unauthorized("token_missing", "no token info found")
 	}
 
 	tokenInfo := ti.(oauth2.TokenInfo)
 	return tokenInfo, nil
 }
+
+// HasScope tells whether the token of the request was granted the given scope.
+func HasScope(ctx *gin.Context, scope string) bool {
+	tokenInfo, err := GetTokenInfo(ctx)
+	if err != nil {
+		return false
+	}
+	for _, granted := range strings.Fields(tokenInfo.GetScope()) {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireScope wraps handler so that requests whose token was not granted scope are rejected with 403.
+func RequireScope(scope string, handler gin.HandlerFunc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !HasScope(ctx, scope) {
+			RespondError(ctx, apperror.Forbidden("scope_required", fmt.Sprintf("the %s scope is required", scope)))
+			return
+		}
+		handler(ctx)
+	}
+}
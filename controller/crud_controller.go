@@ -0,0 +1,1028 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/roksky/bootstrap-api/apperror"
+	"github.com/roksky/bootstrap-api/constants"
+	"github.com/roksky/bootstrap-api/data/response"
+	"github.com/roksky/bootstrap-api/helper"
+	"github.com/roksky/bootstrap-api/model"
+	"github.com/roksky/bootstrap-api/repository"
+	"github.com/roksky/bootstrap-api/service"
+	"github.com/rs/zerolog/log"
+)
+
+// Operation identifies one of the REST operations exposed by a CrudController.
+type Operation int
+
+const (
+	OpCreate Operation = iota
+	OpCreateMany
+	OpUpdate
+	OpUpdateMany
+	OpDelete
+	OpDeleteMany
+	OpFindById
+	OpFindByIds
+	OpSearch
+	OpDeleted
+	OpSortFields
+	OpRestore
+	OpChanges
+	OpVersions
+	OpVersion
+	OpRevert
+	OpStats
+	OpReplace
+)
+
+// allOperations lists every Operation.
+var allOperations = []Operation{OpCreate, OpCreateMany, OpUpdate, OpUpdateMany, OpDelete, OpDeleteMany,
+	OpFindById, OpFindByIds, OpSearch, OpDeleted, OpSortFields, OpRestore, OpChanges,
+	OpVersions, OpVersion, OpRevert, OpStats, OpReplace}
+
+// PurgeAuthorizer tells whether the caller of a request may permanently remove entities.
+type PurgeAuthorizer func(ctx *gin.Context) bool
+
+// IdParser converts a path parameter into an entity id, e.g. uuid.Parse.
+type IdParser[K comparable] func(value string) (K, error)
+
+// SearchBinder builds the search parameters of a request from its query string.
+type SearchBinder[S any] func(ctx *gin.Context) (*S, error)
+
+// CrudController exposes the REST handlers of a BaseService under a group name.
+// Individual operations can be disabled or replaced with Disable, EnableOnly and Override.
+type CrudController[T any, K comparable, S any] struct {
+	service     service.BaseService[T, K, S]
+	groupName   string
+	parseId     IdParser[K]
+	bindSearch  SearchBinder[S]
+	mapCreate   RequestMapper[T]
+	mapUpdate   RequestMapper[T]
+	authEnabled bool
+	canPurge    PurgeAuthorizer
+	disabled    map[Operation]bool
+	overrides   map[Operation]gin.HandlerFunc
+	timeouts    map[Operation]time.Duration
+}
+
+func NewCrudController[T any, K comparable, S any](service service.BaseService[T, K, S], groupName string, parseId IdParser[K], bindSearch SearchBinder[S]) *CrudController[T, K, S] {
+	return &CrudController[T, K, S]{
+		service:     service,
+		groupName:   groupName,
+		parseId:     parseId,
+		bindSearch:  bindSearch,
+		mapCreate:   bindModel[T],
+		mapUpdate:   bindModel[T],
+		authEnabled: true,
+		canPurge:    func(ctx *gin.Context) bool { return HasScope(ctx, constants.AdminScope) },
+		disabled:    map[Operation]bool{},
+		overrides:   map[Operation]gin.HandlerFunc{},
+		timeouts:    map[Operation]time.Duration{},
+	}
+}
+
+// Disable removes the given operations from the controller routes.
+func (controller *CrudController[T, K, S]) Disable(operations ...Operation) *CrudController[T, K, S] {
+	for _, operation := range operations {
+		controller.disabled[operation] = true
+	}
+	return controller
+}
+
+// EnableOnly removes every operation but the given ones from the controller routes, including the
+// operations added later on.
+func (controller *CrudController[T, K, S]) EnableOnly(operations ...Operation) *CrudController[T, K, S] {
+	controller.Disable(allOperations...)
+	for _, operation := range operations {
+		delete(controller.disabled, operation)
+	}
+	return controller
+}
+
+// Override replaces the handler of an operation, keeping its route.
+func (controller *CrudController[T, K, S]) Override(operation Operation, handler gin.HandlerFunc) *CrudController[T, K, S] {
+	controller.overrides[operation] = handler
+	return controller
+}
+
+// SetTimeout bounds the duration of the given operations, all of them when none is given, over
+// the request timeout of the router.
+func (controller *CrudController[T, K, S]) SetTimeout(timeout time.Duration, operations ...Operation) *CrudController[T, K, S] {
+	if len(operations) == 0 {
+		operations = allOperations
+	}
+	for _, operation := range operations {
+		controller.timeouts[operation] = timeout
+	}
+	return controller
+}
+
+// SetCreateMapper sets the mapper of the bodies of the create requests, see MapRequest. By default
+// they are decoded into the entity.
+func (controller *CrudController[T, K, S]) SetCreateMapper(mapper RequestMapper[T]) *CrudController[T, K, S] {
+	controller.mapCreate = mapper
+	return controller
+}
+
+// SetUpdateMapper sets the mapper of the bodies of the update requests, see MapRequest. By default
+// they are decoded into the entity.
+func (controller *CrudController[T, K, S]) SetUpdateMapper(mapper RequestMapper[T]) *CrudController[T, K, S] {
+	controller.mapUpdate = mapper
+	return controller
+}
+
+// SetAuthEnabled toggles token verification for the controller routes.
+func (controller *CrudController[T, K, S]) SetAuthEnabled(enabled bool) *CrudController[T, K, S] {
+	controller.authEnabled = enabled
+	return controller
+}
+
+// SetPurgeAuthorizer replaces the check guarding hard deletes, which by default requires the admin scope.
+func (controller *CrudController[T, K, S]) SetPurgeAuthorizer(canPurge PurgeAuthorizer) *CrudController[T, K, S] {
+	controller.canPurge = canPurge
+	return controller
+}
+
+// Service returns the service backing the controller.
+func (controller *CrudController[T, K, S]) Service() service.BaseService[T, K, S] {
+	return controller.service
+}
+
+func (controller *CrudController[T, K, S]) Create(ctx *gin.Context) {
+	log.Info().Msgf("create %s", controller.groupName)
+
+	tokenInfo, err := GetTokenInfo(ctx)
+	if err != nil {
+		RespondError(ctx, err)
+		return
+	}
+
+	body, err := ctx.GetRawData()
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+	createItem := new(T)
+	if err = mapItem[T, K](controller.mapCreate, body, createItem, false, false); err != nil {
+		RespondError(ctx, err)
+		return
+	}
+	setCreatedBy(createItem, tokenInfo.GetUserID())
+
+	search, err := controller.bindSearch(ctx)
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+
+	item, err := controller.serviceFor(ctx).Create(search, createItem)
+	if err != nil {
+		RespondError(ctx, err)
+	} else {
+		setETag(ctx, item)
+		ctx.JSON(http.StatusCreated, item)
+	}
+}
+
+func (controller *CrudController[T, K, S]) CreateMany(ctx *gin.Context) {
+	log.Info().Msgf("create many %s", controller.groupName)
+
+	partial, err := bulkMode(ctx)
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+
+	tokenInfo, err := GetTokenInfo(ctx)
+	if err != nil {
+		RespondError(ctx, err)
+		return
+	}
+
+	body, err := ctx.GetRawData()
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+	createItems, errs, err := mapItems[T, K](controller.mapCreate, body, false)
+	if err != nil {
+		RespondError(ctx, err)
+		return
+	}
+	for _, item := range createItems {
+		if item != nil {
+			setCreatedBy(item, tokenInfo.GetUserID())
+		}
+	}
+
+	search, err := controller.bindSearch(ctx)
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+
+	if partial {
+		each, ok := capabilityOf[service.EachService[T, K, S]](ctx, controller.serviceFor(ctx))
+		if !ok {
+			return
+		}
+		results := writeEach(ctx, createItems, errs, http.StatusCreated, func(items []*T) []service.ItemResult[T] {
+			return each.CreateEach(search, items)
+		})
+		respondBulk(ctx, http.StatusCreated, results)
+		return
+	}
+	if err = firstError(errs); err != nil {
+		RespondError(ctx, err)
+		return
+	}
+
+	items, err := controller.serviceFor(ctx).CreateMany(search, createItems)
+	if err != nil {
+		RespondError(ctx, err)
+	} else {
+		ctx.JSON(http.StatusCreated, items)
+	}
+}
+
+// Update sets the non zero fields of a JSON body, or applies a merge patch or a JSON patch,
+// according to the Content-Type of the request.
+func (controller *CrudController[T, K, S]) Update(ctx *gin.Context) {
+	switch contentType := ctx.ContentType(); contentType {
+	case MergePatchContentType, JSONPatchContentType:
+		controller.patch(ctx)
+		return
+	case "", gin.MIMEJSON:
+	default:
+		RespondError(ctx, unsupportedPatch(contentType))
+		return
+	}
+	log.Info().Msgf("update %s", controller.groupName)
+
+	tokenInfo, err := GetTokenInfo(ctx)
+	if err != nil {
+		RespondError(ctx, err)
+		return
+	}
+
+	id, err := controller.idParam(ctx)
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+
+	body, err := ctx.GetRawData()
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+	updateItem := new(T)
+	if err = mapItem[T, K](controller.mapUpdate, body, updateItem, true, false); err != nil {
+		RespondError(ctx, err)
+		return
+	}
+	setId(updateItem, id)
+	setUpdatedBy(updateItem, tokenInfo.GetUserID())
+
+	search, err := controller.bindSearch(ctx)
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+
+	stored, err := controller.ifMatch(ctx, id)
+	if err != nil {
+		RespondError(ctx, err)
+		return
+	}
+	// the update only applies to the matched version, should the entity be modified meanwhile
+	if versioned, ok := any(updateItem).(model.Versioned); ok && stored != nil && versioned.GetVersion() == 0 {
+		versioned.SetVersion(any(stored).(model.Versioned).GetVersion())
+	}
+
+	item, err := controller.serviceFor(ctx).Update(search, updateItem)
+	if err != nil {
+		RespondError(ctx, err)
+	} else {
+		setETag(ctx, item)
+		ctx.JSON(http.StatusOK, item)
+	}
+}
+
+func (controller *CrudController[T, K, S]) UpdateMany(ctx *gin.Context) {
+	log.Info().Msgf("update many %s", controller.groupName)
+
+	partial, err := bulkMode(ctx)
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+
+	tokenInfo, err := GetTokenInfo(ctx)
+	if err != nil {
+		RespondError(ctx, err)
+		return
+	}
+
+	body, err := ctx.GetRawData()
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+	updateItems, errs, err := mapItems[T, K](controller.mapUpdate, body, true)
+	if err != nil {
+		RespondError(ctx, err)
+		return
+	}
+	for _, item := range updateItems {
+		if item != nil {
+			setUpdatedBy(item, tokenInfo.GetUserID())
+		}
+	}
+
+	search, err := controller.bindSearch(ctx)
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+
+	if partial {
+		each, ok := capabilityOf[service.EachService[T, K, S]](ctx, controller.serviceFor(ctx))
+		if !ok {
+			return
+		}
+		results := writeEach(ctx, updateItems, errs, http.StatusOK, func(items []*T) []service.ItemResult[T] {
+			return each.UpdateEach(search, items)
+		})
+		respondBulk(ctx, http.StatusOK, results)
+		return
+	}
+	if err = firstError(errs); err != nil {
+		RespondError(ctx, err)
+		return
+	}
+
+	items, err := controller.serviceFor(ctx).UpdateMany(search, updateItems)
+	if err != nil {
+		RespondError(ctx, err)
+	} else {
+		ctx.JSON(http.StatusOK, items)
+	}
+}
+
+// Delete soft deletes an entity, or purges it when the hard query parameter is true.
+func (controller *CrudController[T, K, S]) Delete(ctx *gin.Context) {
+	log.Info().Msgf("delete %s", controller.groupName)
+
+	id, err := controller.idParam(ctx)
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+
+	search, err := controller.bindSearch(ctx)
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+	if _, err = controller.ifMatch(ctx, id); err != nil {
+		RespondError(ctx, err)
+		return
+	}
+	if ctx.Query("hard") == "true" {
+		if !controller.canPurge(ctx) {
+			RespondError(ctx, apperror.Forbidden("purge_forbidden", "not allowed to purge entities"))
+			return
+		}
+		restorer, ok := capabilityOf[service.RestoreService[T, K, S]](ctx, controller.serviceFor(ctx))
+		if !ok {
+			return
+		}
+		err = restorer.Purge(search, []K{id})
+		if err != nil {
+			RespondError(ctx, err)
+		} else {
+			ctx.JSON(http.StatusOK, "purged")
+		}
+		return
+	}
+
+	err = controller.serviceFor(ctx).Delete(search, id)
+	if err != nil {
+		RespondError(ctx, err)
+	} else {
+		ctx.JSON(http.StatusOK, "deleted")
+	}
+}
+
+func (controller *CrudController[T, K, S]) DeleteMany(ctx *gin.Context) {
+	log.Info().Msgf("delete many %s", controller.groupName)
+
+	partial, err := bulkMode(ctx)
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+
+	rawIds, err := helper.ReadJsonAsType[string](ctx.Request.Body)
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+
+	search, err := controller.bindSearch(ctx)
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+
+	if partial {
+		each, ok := capabilityOf[service.EachService[T, K, S]](ctx, controller.serviceFor(ctx))
+		if !ok {
+			return
+		}
+		respondBulk(ctx, http.StatusOK, controller.deleteEach(ctx, each, search, rawIds))
+		return
+	}
+
+	ids, err := controller.parseIds(rawIds)
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+
+	err = controller.serviceFor(ctx).DeleteMany(search, ids)
+	if err != nil {
+		RespondError(ctx, err)
+	} else {
+		ctx.JSON(http.StatusOK, "deleted")
+	}
+}
+
+func (controller *CrudController[T, K, S]) Restore(ctx *gin.Context) {
+	log.Info().Msgf("restore %s", controller.groupName)
+
+	id, err := controller.idParam(ctx)
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+
+	search, err := controller.bindSearch(ctx)
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+
+	restorer, ok := capabilityOf[service.RestoreService[T, K, S]](ctx, controller.serviceFor(ctx))
+	if !ok {
+		return
+	}
+	err = restorer.Restore(search, []K{id})
+	if err != nil {
+		RespondError(ctx, err)
+		return
+	}
+
+	item, err := controller.serviceFor(ctx).FindById(search, id)
+	if err != nil {
+		RespondError(ctx, err)
+	} else {
+		setETag(ctx, item)
+		ctx.JSON(http.StatusOK, item)
+	}
+}
+
+func (controller *CrudController[T, K, S]) FindById(ctx *gin.Context) {
+	log.Info().Msgf("find %s by id", controller.groupName)
+
+	id, err := controller.idParam(ctx)
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+
+	search, err := controller.bindSearch(ctx)
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+
+	item, err := controller.serviceFor(ctx).FindById(search, id)
+	if err != nil {
+		RespondError(ctx, err)
+		return
+	}
+	etag := projectionETag(item, search)
+	if notModified(ctx, etag) {
+		return
+	}
+	setETagHeader(ctx, etag)
+	respondItem(ctx, repository.ProjectedFields(search), item)
+}
+
+func (controller *CrudController[T, K, S]) FindByIds(ctx *gin.Context) {
+	log.Info().Msgf("find %s by ids", controller.groupName)
+
+	ids, err := controller.parseIds(strings.Split(ctx.Param("ids"), ","))
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+
+	search, err := controller.bindSearch(ctx)
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+
+	items, err := controller.serviceFor(ctx).FindByIds(search, ids)
+	if err != nil {
+		RespondError(ctx, err)
+	} else {
+		respondItems(ctx, repository.ProjectedFields(search), items)
+	}
+}
+
+func (controller *CrudController[T, K, S]) SearchAll(ctx *gin.Context) {
+	log.Info().Msgf("search all %s", controller.groupName)
+
+	search, err := controller.bindSearch(ctx)
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+
+	// the presence of the cursor parameter, even empty for the first page, selects keyset pagination
+	if _, useCursor := ctx.GetQuery("cursor"); useCursor {
+		cursorService, ok := capabilityOf[service.CursorService[T, K, S]](ctx, controller.serviceFor(ctx))
+		if !ok {
+			return
+		}
+		result, err := cursorService.SearchByCursor(search)
+		if err != nil {
+			RespondError(ctx, err)
+		} else {
+			respondCursorPage(ctx, repository.ProjectedFields(search), result)
+		}
+		return
+	}
+
+	result, err := controller.serviceFor(ctx).Search(search)
+	if err != nil {
+		RespondError(ctx, err)
+	} else {
+		respondPage(ctx, repository.ProjectedFields(search), result)
+	}
+}
+
+func (controller *CrudController[T, K, S]) GetDeleted(ctx *gin.Context) {
+	log.Info().Msgf("search all deleted %s", controller.groupName)
+
+	search, err := controller.bindSearch(ctx)
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+
+	ids, err := controller.serviceFor(ctx).Deleted(search)
+	if err != nil {
+		RespondError(ctx, err)
+	} else {
+		ctx.JSON(http.StatusOK, ids)
+	}
+}
+
+// Changes serves the delta sync feed: the entities upserted and deleted since the since query
+// parameter, which takes the syncToken of the previous response or an RFC 3339 timestamp.
+func (controller *CrudController[T, K, S]) Changes(ctx *gin.Context) {
+	log.Info().Msgf("changes of %s", controller.groupName)
+
+	search, err := controller.bindSearch(ctx)
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+
+	changesService, ok := capabilityOf[service.ChangesService[T, K, S]](ctx, controller.serviceFor(ctx))
+	if !ok {
+		return
+	}
+	changes, err := changesService.Changes(search, ctx.Query("since"))
+	if err != nil {
+		RespondError(ctx, err)
+	} else {
+		ctx.JSON(http.StatusOK, changes)
+	}
+}
+
+// Stats computes statistics over the entities matching the search, e.g.
+// GET /org/stats?groupBy=dateCreated:month&metric=count, see repository.AggregateRequest.
+func (controller *CrudController[T, K, S]) Stats(ctx *gin.Context) {
+	log.Info().Msgf("stats %s", controller.groupName)
+
+	search, err := controller.bindSearch(ctx)
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+
+	request := &repository.AggregateRequest{
+		GroupBy: queryList(ctx, "groupBy"),
+		Metrics: queryList(ctx, "metric"),
+	}
+	statsService, ok := capabilityOf[service.StatsService[T, K, S]](ctx, controller.serviceFor(ctx))
+	if !ok {
+		return
+	}
+	result, err := statsService.Stats(search, request)
+	if err != nil {
+		RespondError(ctx, err)
+	} else {
+		ctx.JSON(http.StatusOK, result)
+	}
+}
+
+// Versions lists the versions of an entity keeping a history, or with the at query parameter, an
+// RFC 3339 timestamp, the version in effect at that time.
+func (controller *CrudController[T, K, S]) Versions(ctx *gin.Context) {
+	log.Info().Msgf("versions of %s", controller.groupName)
+
+	history, id, ok := controller.historyRequest(ctx)
+	if !ok {
+		return
+	}
+
+	var at *time.Time
+	if value := ctx.Query("at"); value != "" {
+		parsed, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			RespondError(ctx, apperror.Validation("invalid_timestamp", value+" is not an RFC 3339 timestamp"))
+			return
+		}
+		at = &parsed
+	}
+
+	versions, err := history.Versions(id, at)
+	if err != nil {
+		RespondError(ctx, err)
+	} else {
+		ctx.JSON(http.StatusOK, versions)
+	}
+}
+
+func (controller *CrudController[T, K, S]) Version(ctx *gin.Context) {
+	log.Info().Msgf("version of %s", controller.groupName)
+
+	history, id, ok := controller.historyRequest(ctx)
+	if !ok {
+		return
+	}
+	version, err := versionParam(ctx)
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+
+	entityVersion, err := history.Version(id, version)
+	if err != nil {
+		RespondError(ctx, err)
+	} else {
+		ctx.JSON(http.StatusOK, entityVersion)
+	}
+}
+
+// Revert replaces an entity with the content of one of its versions, through the replace of the
+// service. The If-Match header, if any, is checked against the current version of the entity.
+func (controller *CrudController[T, K, S]) Revert(ctx *gin.Context) {
+	log.Info().Msgf("revert %s", controller.groupName)
+
+	history, id, ok := controller.historyRequest(ctx)
+	if !ok {
+		return
+	}
+	version, err := versionParam(ctx)
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+
+	replacer, ok := capabilityOf[service.ReplaceService[T, K, S]](ctx, controller.serviceFor(ctx))
+	if !ok {
+		return
+	}
+	tokenInfo, err := GetTokenInfo(ctx)
+	if err != nil {
+		RespondError(ctx, err)
+		return
+	}
+	search, err := controller.bindSearch(ctx)
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+
+	current, err := controller.serviceFor(ctx).FindById(nil, id)
+	if err != nil {
+		RespondError(ctx, err)
+		return
+	}
+	if err = checkIfMatch(ctx, id, current); err != nil {
+		RespondError(ctx, err)
+		return
+	}
+	entityVersion, err := history.Version(id, version)
+	if err != nil {
+		RespondError(ctx, err)
+		return
+	}
+	item, err := service.Reverted[T, K](current, entityVersion)
+	if err != nil {
+		RespondError(ctx, err)
+		return
+	}
+	setUpdatedBy(item, tokenInfo.GetUserID())
+
+	// the item goes through the replace of the service, its validation and overrides included
+	item, err = replacer.Replace(search, item)
+	if err != nil {
+		RespondError(ctx, err)
+	} else {
+		setETag(ctx, item)
+		ctx.JSON(http.StatusOK, item)
+	}
+}
+
+// historyRequest returns the history service and the entity id of a version request, after
+// responding with an error when they are not available.
+func (controller *CrudController[T, K, S]) historyRequest(ctx *gin.Context) (service.HistoryService[T, K, S], K, bool) {
+	var zero K
+	history, ok := controller.serviceFor(ctx).(service.HistoryService[T, K, S])
+	if !ok {
+		RespondError(ctx, repository.ErrHistoryDisabled)
+		return nil, zero, false
+	}
+	id, err := controller.idParam(ctx)
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return nil, zero, false
+	}
+	return history, id, true
+}
+
+func versionParam(ctx *gin.Context) (int64, error) {
+	value := ctx.Param("version")
+	version, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || version < 1 {
+		return 0, apperror.Validation("invalid_version", value+" is not a valid version")
+	}
+	return version, nil
+}
+
+// SortFields lists the fields the entity can be sorted on, for documentation purposes.
+func (controller *CrudController[T, K, S]) SortFields(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, repository.SortRegistryOf[T, S](nil, nil).AllowedFields())
+}
+
+func (controller *CrudController[T, K, S]) GroupName() string {
+	return controller.groupName
+}
+
+// crudRoute is the route of an operation of a CrudController.
+type crudRoute struct {
+	operation   Operation
+	method      HttpMethod
+	urlTemplate string
+	handler     gin.HandlerFunc
+}
+
+func (controller *CrudController[T, K, S]) Handlers() []*HttpFunc {
+	routes := []crudRoute{
+		{OpSearch, GET, "", controller.SearchAll},
+		{OpFindById, GET, "/:id", controller.FindById},
+		{OpFindByIds, GET, "s/:ids", controller.FindByIds},
+		{OpCreate, POST, "", controller.Create},
+		{OpCreateMany, POST, "s", controller.CreateMany},
+		{OpUpdate, PATCH, "/:id", controller.Update},
+		{OpReplace, PUT, "/:id", controller.Replace},
+		{OpUpdateMany, PATCH, "s", controller.UpdateMany},
+		{OpDelete, DELETE, "/:id", controller.Delete},
+		{OpDeleteMany, DELETE, "s", controller.DeleteMany},
+		{OpDeleted, GET, "/deleted", controller.GetDeleted},
+		{OpSortFields, GET, "/sort-fields", controller.SortFields},
+		{OpRestore, POST, "/:id/restore", controller.Restore},
+		{OpChanges, GET, "/changes", controller.Changes},
+		{OpStats, GET, "/stats", controller.Stats},
+	}
+	if _, historized := any(new(T)).(model.Historized); historized {
+		routes = append(routes, []crudRoute{
+			{OpVersions, GET, "/:id/versions", controller.Versions},
+			{OpVersion, GET, "/:id/versions/:version", controller.Version},
+			{OpRevert, POST, "/:id/versions/:version/revert", controller.Revert},
+		}...)
+	}
+
+	handlers := make([]*HttpFunc, 0, len(routes))
+	for _, route := range routes {
+		if controller.disabled[route.operation] {
+			continue
+		}
+		handler := route.handler
+		if override, ok := controller.overrides[route.operation]; ok {
+			handler = override
+		}
+		httpFunc := NewHttpFunc(route.method, route.urlTemplate, handler)
+		if timeout, ok := controller.timeouts[route.operation]; ok {
+			httpFunc.WithTimeout(timeout)
+		}
+		handlers = append(handlers, httpFunc)
+	}
+	return handlers
+}
+
+func (controller *CrudController[T, K, S]) IsAuthEnabled() bool {
+	return controller.authEnabled
+}
+
+// deleteEach deletes the entities one by one, reporting the outcome of each id, invalid ones included.
+func (controller *CrudController[T, K, S]) deleteEach(ctx *gin.Context, each service.EachService[T, K, S], search *S, rawIds []string) []response.BulkItemResult[*T] {
+	results := make([]response.BulkItemResult[*T], len(rawIds))
+	ids := make([]K, 0, len(rawIds))
+	indexes := make([]int, 0, len(rawIds))
+	for i, rawId := range rawIds {
+		id, err := controller.parseId(strings.TrimSpace(rawId))
+		if err != nil {
+			results[i] = bulkItemResult[*T](ctx, i, nil, invalidId(rawId), http.StatusOK)
+			results[i].Id = rawId
+			continue
+		}
+		ids = append(ids, id)
+		indexes = append(indexes, i)
+	}
+
+	for j, err := range each.DeleteEach(search, ids) {
+		i := indexes[j]
+		results[i] = bulkItemResult[*T](ctx, i, nil, err, http.StatusOK)
+		results[i].Id = rawIds[i]
+	}
+	return results
+}
+
+// serviceFor binds the service to the context of the request, so that its queries are cancelled
+// when the client goes away or the request times out.
+func (controller *CrudController[T, K, S]) serviceFor(ctx *gin.Context) service.BaseService[T, K, S] {
+	return service.Bind(controller.service, ctx.Request.Context())
+}
+
+// capabilityOf returns svc as the optional service interface C, after responding with
+// repository.ErrUnsupported when svc does not implement it.
+func capabilityOf[C any](ctx *gin.Context, svc any) (C, bool) {
+	capability, ok := svc.(C)
+	if !ok {
+		RespondError(ctx, repository.ErrUnsupported)
+	}
+	return capability, ok
+}
+
+func (controller *CrudController[T, K, S]) idParam(ctx *gin.Context) (K, error) {
+	value := ctx.Param("id")
+	if value == "" {
+		var zero K
+		return zero, apperror.Validation("id_missing", "id is required")
+	}
+	id, err := controller.parseId(value)
+	if err != nil {
+		return id, invalidId(value)
+	}
+	return id, nil
+}
+
+func invalidId(value string) error {
+	return apperror.Validation("invalid_id", value+" is not a valid id")
+}
+
+func (controller *CrudController[T, K, S]) parseIds(values []string) ([]K, error) {
+	ids := make([]K, 0, len(values))
+	for _, value := range values {
+		id, err := controller.parseId(strings.TrimSpace(value))
+		if err != nil {
+			return nil, invalidId(value)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// BindSearch is the default SearchBinder. It fills the fields of S tagged with `query`
+// from the request query string, see helper.BindQuery.
+func BindSearch[S any](ctx *gin.Context) (*S, error) {
+	search := new(S)
+	if err := helper.BindQuery(ctx.Request.URL.Query(), search); err != nil {
+		return nil, err
+	}
+	return search, nil
+}
+
+// queryList returns the values of a query parameter given repeatedly or separated by commas.
+func queryList(ctx *gin.Context, name string) []string {
+	var values []string
+	for _, param := range ctx.QueryArray(name) {
+		for _, value := range strings.Split(param, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// bulkMode reads the mode query parameter of a bulk request: atomic, the default, writes all the items
+// or none of them, and partial writes the items that can be and reports the outcome of each.
+func bulkMode(ctx *gin.Context) (bool, error) {
+	switch mode := ctx.DefaultQuery("mode", "atomic"); mode {
+	case "atomic":
+		return false, nil
+	case "partial":
+		return true, nil
+	default:
+		return false, fmt.Errorf("unknown mode %s, expected atomic or partial", mode)
+	}
+}
+
+// respondBulk sends the outcome of a partial bulk request, with the 207 Multi-Status status when
+// some items failed.
+func respondBulk[T any](ctx *gin.Context, status int, results []response.BulkItemResult[T]) {
+	for _, result := range results {
+		if result.Error != nil {
+			status = http.StatusMultiStatus
+			break
+		}
+	}
+	ctx.JSON(status, results)
+}
+
+// writeEach writes the items of a partial bulk request which could be mapped, and reports the
+// outcome of each item, the mapping errors included.
+func writeEach[T any](ctx *gin.Context, items []*T, errs []error, successStatus int, write func([]*T) []service.ItemResult[T]) []response.BulkItemResult[*T] {
+	bulkResults := make([]response.BulkItemResult[*T], len(items))
+	mapped := make([]*T, 0, len(items))
+	indexes := make([]int, 0, len(items))
+	for i, item := range items {
+		if errs[i] != nil {
+			bulkResults[i] = bulkItemResult[*T](ctx, i, nil, errs[i], successStatus)
+			continue
+		}
+		mapped = append(mapped, item)
+		indexes = append(indexes, i)
+	}
+
+	for j, result := range write(mapped) {
+		i := indexes[j]
+		bulkResults[i] = bulkItemResult(ctx, i, result.Item, result.Err, successStatus)
+	}
+	return bulkResults
+}
+
+// firstError returns the first of the mapping errors of the items of an atomic bulk request.
+func firstError(errs []error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func bulkItemResult[T any](ctx *gin.Context, index int, item T, err error, successStatus int) response.BulkItemResult[T] {
+	if err != nil {
+		problem := ProblemOf(ctx, err)
+		return response.BulkItemResult[T]{Index: index, Status: problem.Status, Error: &problem}
+	}
+	return response.BulkItemResult[T]{Index: index, Status: successStatus, Item: item}
+}
+
+func setId[T any, K comparable](item *T, id K) {
+	if identifiable, ok := any(item).(model.Identifiable[K]); ok {
+		identifiable.SetId(id)
+	}
+}
+
+func setCreatedBy[T any](item *T, user string) {
+	if auditable, ok := any(item).(model.Auditable); ok {
+		auditable.SetCreatedBy(user)
+		auditable.SetUpdatedBy(user)
+	}
+}
+
+func setUpdatedBy[T any](item *T, user string) {
+	if auditable, ok := any(item).(model.Auditable); ok {
+		auditable.SetUpdatedBy(user)
+	}
+}
@@ -0,0 +1,150 @@
+package controller
+
+import (
+	"hash/fnv"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/roksky/bootstrap-api/model"
+	"github.com/roksky/bootstrap-api/repository"
+)
+
+// ErrPreconditionFailed is returned when the If-Match header of a write request does not match the
+// entity tag of the stored entity, which was modified since the client read it.
+var ErrPreconditionFailed = repository.ErrPreconditionFailed
+
+// ETagOf returns the entity tag of an entity, a strong one quoted as sent in the ETag header: its
+// version for versioned entities, or the time of its last update, read from its DateUpdated field.
+// It is empty for the entities having neither.
+func ETagOf[T any](item *T) string {
+	if item == nil {
+		return ""
+	}
+	if versioned, ok := any(item).(model.Versioned); ok {
+		return `"v` + strconv.FormatInt(versioned.GetVersion(), 10) + `"`
+	}
+	if updatedAt, ok := updatedAtOf(item); ok {
+		return `"t` + strconv.FormatInt(updatedAt.UnixMicro(), 10) + `"`
+	}
+	return ""
+}
+
+// projectionETag returns the entity tag of the representation of item sent for searchParams: the
+// one of the entity, see ETagOf, when it is sent whole, and one also identifying the fields and
+// relations requested otherwise, so that a projection is never taken for another.
+func projectionETag[T any, S any](item *T, searchParams *S) string {
+	etag := ETagOf(item)
+	pageable, ok := any(searchParams).(repository.Pageable)
+	if etag == "" || !ok {
+		return etag
+	}
+	pageRequest := pageable.GetPageRequest()
+	if pageRequest.Fields == "" && pageRequest.Include == "" {
+		return etag
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(pageRequest.Fields + "\x00" + pageRequest.Include))
+	return strings.TrimSuffix(etag, `"`) + "-" + strconv.FormatUint(uint64(hash.Sum32()), 36) + `"`
+}
+
+// updatedAtOf returns the time of the last update of item, read from its DateUpdated field.
+func updatedAtOf[T any](item *T) (time.Time, bool) {
+	value := reflect.Indirect(reflect.ValueOf(item))
+	if value.Kind() != reflect.Struct {
+		return time.Time{}, false
+	}
+	field := value.FieldByName("DateUpdated")
+	if !field.IsValid() {
+		return time.Time{}, false
+	}
+	updatedAt, ok := field.Interface().(time.Time)
+	return updatedAt, ok && !updatedAt.IsZero()
+}
+
+// setETag sets the ETag header of the response to the entity tag of item, when it has one.
+func setETag[T any](ctx *gin.Context, item *T) {
+	setETagHeader(ctx, ETagOf(item))
+}
+
+func setETagHeader(ctx *gin.Context, etag string) {
+	if etag != "" {
+		ctx.Header("ETag", etag)
+	}
+}
+
+// notModified tells whether the If-None-Match header of the request matches etag, in which case
+// the response is sent with the 304 Not Modified status and no body.
+func notModified(ctx *gin.Context, etag string) bool {
+	header := ctx.GetHeader("If-None-Match")
+	if header == "" || !matchesETag(header, etag, true) {
+		return false
+	}
+	setETagHeader(ctx, etag)
+	ctx.Status(http.StatusNotModified)
+	return true
+}
+
+// checkIfMatch returns ErrPreconditionFailed when the If-Match header does not match stored, and
+// otherwise sets stored as the precondition of the writes of the request, see repository.WithPrecondition.
+func checkIfMatch[T any, K comparable](ctx *gin.Context, id K, stored *T) error {
+	header := ctx.GetHeader("If-Match")
+	if header == "" {
+		return nil
+	}
+	if !matchesETag(header, ETagOf(stored), false) {
+		return ErrPreconditionFailed
+	}
+	if strings.TrimSpace(header) == "*" {
+		return nil
+	}
+	precondition := repository.Precondition{Id: id}
+	if versioned, ok := any(stored).(model.Versioned); ok {
+		precondition.Version = versioned.GetVersion()
+	} else {
+		precondition.UpdatedAt, _ = updatedAtOf(stored)
+	}
+	ctx.Request = ctx.Request.WithContext(repository.WithPrecondition(ctx.Request.Context(), precondition))
+	return nil
+}
+
+// ifMatch verifies the If-Match header of a write request on the entity with the given id, see
+// checkIfMatch, and returns the stored entity. The entity is not read, and nil is returned, when
+// the request has no If-Match header.
+func (controller *CrudController[T, K, S]) ifMatch(ctx *gin.Context, id K) (*T, error) {
+	if ctx.GetHeader("If-Match") == "" {
+		return nil, nil
+	}
+	stored, err := controller.serviceFor(ctx).FindById(nil, id)
+	if err != nil {
+		return nil, err
+	}
+	return stored, checkIfMatch(ctx, id, stored)
+}
+
+// matchesETag tells whether a list of entity tags, or *, matches etag. The weak comparison of
+// If-None-Match ignores the W/ prefix, while weak tags never match with the strong one of If-Match.
+func matchesETag(header string, etag string, weak bool) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	if etag == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if strings.HasPrefix(candidate, "W/") {
+			if !weak {
+				continue
+			}
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
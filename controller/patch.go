@@ -0,0 +1,206 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/roksky/bootstrap-api/apperror"
+	"github.com/roksky/bootstrap-api/helper"
+	"github.com/roksky/bootstrap-api/model"
+	"github.com/roksky/bootstrap-api/service"
+	"github.com/rs/zerolog/log"
+)
+
+// Content types of the bodies of the PATCH requests applied to the stored entity.
+const (
+	MergePatchContentType = "application/merge-patch+json"
+	JSONPatchContentType  = "application/json-patch+json"
+)
+
+// replacement builds the body replacing the stored entity from the one of the request, and returns
+// it with the entity it is mapped onto.
+type replacement[T any] func(stored *T, body []byte) (*T, json.RawMessage, error)
+
+// Replace overwrites an entity with the body of the request, zero values included, e.g.
+// PUT /org/:id. The body is mapped onto a zeroed entity, so that the fields it does not give are
+// cleared, whether the update mapper of the controller, see SetUpdateMapper, maps them or not.
+func (controller *CrudController[T, K, S]) Replace(ctx *gin.Context) {
+	log.Info().Msgf("replace %s", controller.groupName)
+
+	controller.replace(ctx, func(stored *T, body []byte) (*T, json.RawMessage, error) {
+		return new(T), body, nil
+	})
+}
+
+// patch applies the merge patch (RFC 7386) or the JSON patch (RFC 6902) of the request to the
+// stored entity, which is then replaced with the result, see Replace. The members the patch removes,
+// e.g. set to null by a merge patch, are cleared.
+func (controller *CrudController[T, K, S]) patch(ctx *gin.Context) {
+	log.Info().Msgf("patch %s", controller.groupName)
+
+	if ctx.ContentType() == JSONPatchContentType {
+		controller.replace(ctx, applyJSONPatch[T])
+	} else {
+		controller.replace(ctx, applyMergePatch[T])
+	}
+}
+
+func (controller *CrudController[T, K, S]) replace(ctx *gin.Context, replacementOf replacement[T]) {
+	replacer, ok := capabilityOf[service.ReplaceService[T, K, S]](ctx, controller.serviceFor(ctx))
+	if !ok {
+		return
+	}
+
+	tokenInfo, err := GetTokenInfo(ctx)
+	if err != nil {
+		RespondError(ctx, err)
+		return
+	}
+
+	id, err := controller.idParam(ctx)
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+
+	body, err := ctx.GetRawData()
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+
+	search, err := controller.bindSearch(ctx)
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+
+	// the stored entity is read whole, whatever the fields requested for the response
+	item, err := controller.serviceFor(ctx).FindById(nil, id)
+	if err != nil {
+		RespondError(ctx, err)
+		return
+	}
+	if err = checkIfMatch(ctx, id, item); err != nil {
+		RespondError(ctx, err)
+		return
+	}
+	var storedVersion int64
+	if versioned, ok := any(item).(model.Versioned); ok {
+		storedVersion = versioned.GetVersion()
+	}
+	item, replacingBody, err := replacementOf(item, body)
+	if err != nil {
+		RespondError(ctx, err)
+		return
+	}
+	versioned, isVersioned := any(item).(model.Versioned)
+	if err = mapItem[T, K](controller.mapUpdate, replacingBody, item, true, false); err != nil {
+		RespondError(ctx, err)
+		return
+	}
+	// without a version in the body, the entity is only replaced if it was not modified since read
+	if isVersioned && versioned.GetVersion() == 0 {
+		versioned.SetVersion(storedVersion)
+	}
+	setId(item, id)
+	setUpdatedBy(item, tokenInfo.GetUserID())
+
+	item, err = replacer.Replace(search, item)
+	if err != nil {
+		RespondError(ctx, err)
+	} else {
+		setETag(ctx, item)
+		ctx.JSON(http.StatusOK, item)
+	}
+}
+
+func applyMergePatch[T any](stored *T, body []byte) (*T, json.RawMessage, error) {
+	var patch interface{}
+	if err := json.Unmarshal(body, &patch); err != nil {
+		return nil, nil, apperror.Invalid(err)
+	}
+	document, err := jsonDocument(stored)
+	if err != nil {
+		return nil, nil, err
+	}
+	return patchedItem(stored, document, helper.MergePatch(document, patch))
+}
+
+func applyJSONPatch[T any](stored *T, body []byte) (*T, json.RawMessage, error) {
+	var operations []helper.PatchOperation
+	if err := json.Unmarshal(body, &operations); err != nil {
+		return nil, nil, apperror.Invalid(err)
+	}
+	document, err := jsonDocument(stored)
+	if err != nil {
+		return nil, nil, err
+	}
+	patched, err := helper.JSONPatch(document, operations)
+	if err != nil {
+		return nil, nil, err
+	}
+	return patchedItem(stored, document, patched)
+}
+
+// patchedItem clears the fields of stored whose member of document the patched document no longer
+// has, for a request mapper leaving the absent members unchanged not to keep them.
+func patchedItem[T any](stored *T, document interface{}, patched interface{}) (*T, json.RawMessage, error) {
+	body, err := json.Marshal(patched)
+	if err != nil {
+		return nil, nil, err
+	}
+	members, _ := document.(map[string]interface{})
+	patchedMembers, _ := patched.(map[string]interface{})
+	for name := range members {
+		if _, ok := patchedMembers[name]; !ok {
+			clearMember(reflect.ValueOf(stored).Elem(), name)
+		}
+	}
+	return stored, body, nil
+}
+
+// clearMember zeroes the field of the struct value encoded as the JSON member name, looking into
+// its embedded structs.
+func clearMember(value reflect.Value, name string) bool {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if field.Anonymous && tag == "" && field.Type.Kind() == reflect.Struct {
+			if clearMember(value.Field(i), name) {
+				return true
+			}
+			continue
+		}
+		if tag == name || (tag == "" && field.Name == name) {
+			value.Field(i).SetZero()
+			return true
+		}
+	}
+	return false
+}
+
+// jsonDocument returns the JSON document of an entity, as decoded into an interface{}.
+func jsonDocument[T any](item *T) (interface{}, error) {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	var document interface{}
+	if err = json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+func unsupportedPatch(contentType string) error {
+	return apperror.New(apperror.KindUnsupported, "unsupported_media_type",
+		fmt.Sprintf("%s is not supported, expected application/json, %s or %s", contentType, MergePatchContentType, JSONPatchContentType))
+}
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/roksky/bootstrap-api/apperror"
+	"github.com/roksky/bootstrap-api/constants"
+	"github.com/roksky/bootstrap-api/data/response"
+	"github.com/roksky/bootstrap-api/repository"
+	"github.com/rs/zerolog/log"
+)
+
+// RespondError aborts the request with err rendered as an application/problem+json response, see ProblemOf.
+func RespondError(ctx *gin.Context, err error) {
+	problem := ProblemOf(ctx, err)
+	ctx.Header("Content-Type", response.ProblemContentType)
+	ctx.AbortWithStatusJSON(problem.Status, problem)
+}
+
+// StatusClientClosedRequest is the status of the requests cancelled by their client.
+const StatusClientClosedRequest = 499
+
+// ProblemOf maps err to an RFC 7807 problem whose status depends on its kind, see apperror.From.
+// Untyped errors of a cancelled or timed out request are reported as such.
+func ProblemOf(ctx *gin.Context, err error) response.Problem {
+	typed := apperror.From(err)
+	if contextErr := ctx.Request.Context().Err(); contextErr != nil && typed.Kind == apperror.KindInternal {
+		typed = apperror.From(contextErr)
+	}
+	status := statusOf(typed.Kind)
+	if status == http.StatusInternalServerError {
+		log.Error().Err(err).Str("path", ctx.Request.URL.Path).Msg("request failed")
+	}
+
+	requestId := repository.RequestIdFromContext(ctx.Request.Context())
+	if requestId == "" {
+		requestId = ctx.GetHeader(constants.RequestIdHeader)
+	}
+	problem := response.Problem{
+		Type:       "about:blank",
+		Title:      statusText(status),
+		Status:     status,
+		Code:       typed.Code,
+		Detail:     typed.Detail,
+		Instance:   ctx.Request.URL.Path,
+		RequestId:  requestId,
+		Extensions: typed.Extensions,
+	}
+	for _, field := range typed.LocalizedFields(apperror.Translator(ctx.GetHeader("Accept-Language"))) {
+		problem.Errors = append(problem.Errors, response.FieldProblem{
+			Field:   field.Field,
+			Rule:    field.Rule,
+			Param:   field.Param,
+			Message: field.Message,
+		})
+	}
+	return problem
+}
+
+func statusText(status int) string {
+	if status == StatusClientClosedRequest {
+		return "Client Closed Request"
+	}
+	return http.StatusText(status)
+}
+
+func statusOf(kind apperror.Kind) int {
+	switch kind {
+	case apperror.KindNotFound:
+		return http.StatusNotFound
+	case apperror.KindValidation:
+		return http.StatusBadRequest
+	case apperror.KindConflict:
+		return http.StatusConflict
+	case apperror.KindForbidden:
+		return http.StatusForbidden
+	case apperror.KindUnauthorized:
+		return http.StatusUnauthorized
+	case apperror.KindPrecondition:
+		return http.StatusPreconditionFailed
+	case apperror.KindUnsupported:
+		return http.StatusUnsupportedMediaType
+	case apperror.KindTimeout:
+		return http.StatusGatewayTimeout
+	case apperror.KindCanceled:
+		return StatusClientClosedRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
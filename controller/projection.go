@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/roksky/bootstrap-api/data/response"
+)
+
+// projection is an entity trimmed to the fields requested by the fields query parameter.
+type projection = map[string]json.RawMessage
+
+// respondItem sends item, trimmed to fields unless fields is nil.
+func respondItem[T any](ctx *gin.Context, fields []string, item *T) {
+	if fields == nil {
+		ctx.JSON(http.StatusOK, item)
+		return
+	}
+	projected, err := project(item, fields)
+	if err != nil {
+		RespondError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, projected)
+}
+
+// respondItems sends items, trimmed to fields unless fields is nil.
+func respondItems[T any](ctx *gin.Context, fields []string, items []*T) {
+	if fields == nil {
+		ctx.JSON(http.StatusOK, items)
+		return
+	}
+	projected, err := projectAll(items, fields)
+	if err != nil {
+		RespondError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, projected)
+}
+
+// respondPage sends a page of a search, its items trimmed to fields unless fields is nil.
+func respondPage[T any](ctx *gin.Context, fields []string, page response.PagedResult[*T]) {
+	if fields == nil {
+		ctx.JSON(http.StatusOK, page)
+		return
+	}
+	items, err := projectAll(page.Items, fields)
+	if err != nil {
+		RespondError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, response.PagedResult[projection]{
+		TotalItems: page.TotalItems,
+		PageNumber: page.PageNumber,
+		PageSize:   page.PageSize,
+		Items:      items,
+	})
+}
+
+// respondCursorPage sends a page of a keyset paginated search, its items trimmed to fields unless fields is nil.
+func respondCursorPage[T any](ctx *gin.Context, fields []string, page response.CursorResult[*T]) {
+	if fields == nil {
+		ctx.JSON(http.StatusOK, page)
+		return
+	}
+	items, err := projectAll(page.Items, fields)
+	if err != nil {
+		RespondError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, response.CursorResult[projection]{
+		PageSize:   page.PageSize,
+		NextCursor: page.NextCursor,
+		Items:      items,
+	})
+}
+
+func projectAll[T any](items []*T, fields []string) ([]projection, error) {
+	projected := make([]projection, 0, len(items))
+	for _, item := range items {
+		trimmed, err := project(item, fields)
+		if err != nil {
+			return nil, err
+		}
+		projected = append(projected, trimmed)
+	}
+	return projected, nil
+}
+
+// project keeps the given top level fields of the JSON of item.
+func project(item any, fields []string) (projection, error) {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	var all projection
+	if err = json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	projected := make(projection, len(fields))
+	for _, field := range fields {
+		if value, ok := all[field]; ok {
+			projected[field] = value
+		}
+	}
+	return projected, nil
+}
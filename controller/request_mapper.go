@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"encoding/json"
+
+	"github.com/roksky/bootstrap-api/apperror"
+	"github.com/roksky/bootstrap-api/model"
+)
+
+// RequestMapper maps the JSON body of a write request, or of one item of a bulk request, onto an
+// entity: a new one, or for a replace or a patch the stored one.
+type RequestMapper[T any] func(body json.RawMessage, item *T) error
+
+// MapRequest returns the RequestMapper decoding and validating the request DTO D, then mapping it
+// onto the entity with mapTo, e.g.
+//
+//	controller.SetCreateMapper(MapRequest(func(req *request.CreateOrganizationRequest, org *model.Organization) {
+//		org.Name = req.Name
+//	}))
+func MapRequest[D any, T any](mapTo func(request *D, item *T)) RequestMapper[T] {
+	return func(body json.RawMessage, item *T) error {
+		request := new(D)
+		if err := json.Unmarshal(body, request); err != nil {
+			return apperror.Invalid(err)
+		}
+		if err := apperror.Validator().Struct(request); err != nil {
+			return apperror.Invalid(err)
+		}
+		mapTo(request, item)
+		return nil
+	}
+}
+
+// bindModel is the RequestMapper of the operations without a request DTO: the entity is replaced
+// with the one decoded from the body.
+func bindModel[T any](body json.RawMessage, item *T) error {
+	decoded := new(T)
+	if err := json.Unmarshal(body, decoded); err != nil {
+		return apperror.Invalid(err)
+	}
+	*item = *decoded
+	return nil
+}
+
+// mapItem maps a request body onto item and clears its server-managed fields, but for the version
+// of an update and the id of a bulk update.
+func mapItem[T any, K comparable](mapper RequestMapper[T], body json.RawMessage, item *T, update bool, bulk bool) error {
+	if err := mapper(body, item); err != nil {
+		return err
+	}
+	if serverManaged, ok := any(item).(model.ServerManaged); ok {
+		serverManaged.ClearServerFields()
+	}
+	if !update {
+		if versioned, ok := any(item).(model.Versioned); ok {
+			versioned.SetVersion(0)
+		}
+		return nil
+	}
+
+	keys := new(T)
+	if err := bindModel(body, keys); err != nil {
+		return err
+	}
+	if identifiable, ok := any(keys).(model.Identifiable[K]); ok && bulk {
+		setId(item, identifiable.GetId())
+	}
+	if versioned, ok := any(keys).(model.Versioned); ok && versioned.GetVersion() != 0 {
+		if itemVersioned, ok := any(item).(model.Versioned); ok {
+			itemVersioned.SetVersion(versioned.GetVersion())
+		}
+	}
+	return nil
+}
+
+// mapItems maps the items of a bulk request body, see mapItem, returning the error of each item
+// that cannot be mapped. The error is only returned when the body is not a JSON array.
+func mapItems[T any, K comparable](mapper RequestMapper[T], body []byte, update bool) ([]*T, []error, error) {
+	var bodies []json.RawMessage
+	if err := json.Unmarshal(body, &bodies); err != nil {
+		return nil, nil, apperror.Invalid(err)
+	}
+	items := make([]*T, len(bodies))
+	errs := make([]error, len(bodies))
+	for i, itemBody := range bodies {
+		items[i] = new(T)
+		if errs[i] = mapItem[T, K](mapper, itemBody, items[i], update, true); errs[i] != nil {
+			items[i] = nil
+		}
+	}
+	return items, errs, nil
+}
@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/roksky/bootstrap-api/apperror"
+	"github.com/roksky/bootstrap-api/constants"
+	"github.com/roksky/bootstrap-api/model"
+	"github.com/roksky/bootstrap-api/repository"
+	"github.com/roksky/bootstrap-api/service"
+	"github.com/rs/zerolog/log"
+)
+
+// SystemUserController lets administrators, holding the admin scope, list, search and soft delete the
+// system users, e.g. GET /users?userNamePrefix=jo&active=true, and deactivate or reactivate them with
+// POST /users/:id/deactivate and POST /users/:id/reactivate.
+type SystemUserController struct {
+	*CrudController[model.SystemUser, uuid.UUID, repository.SystemUserSearch]
+}
+
+func NewSystemUserController(service service.BaseService[model.SystemUser, uuid.UUID, repository.SystemUserSearch]) *SystemUserController {
+	controller := NewCrudController(service, "/users", uuid.Parse, BindSearch[repository.SystemUserSearch])
+	controller.Disable(OpChanges)
+	return &SystemUserController{
+		CrudController: controller,
+	}
+}
+
+func (controller *SystemUserController) Handlers() []*HttpFunc {
+	handlers := append(controller.CrudController.Handlers(),
+		NewHttpFunc(POST, "/:id/deactivate", controller.Deactivate),
+		NewHttpFunc(POST, "/:id/reactivate", controller.Reactivate),
+	)
+	for _, handler := range handlers {
+		handler.httpFunc = RequireScope(constants.AdminScope, handler.httpFunc)
+	}
+	return handlers
+}
+
+func (controller *SystemUserController) Deactivate(ctx *gin.Context) {
+	log.Info().Msg("deactivate user")
+
+	controller.setActive(ctx, false)
+}
+
+func (controller *SystemUserController) Reactivate(ctx *gin.Context) {
+	log.Info().Msg("reactivate user")
+
+	controller.setActive(ctx, true)
+}
+
+func (controller *SystemUserController) setActive(ctx *gin.Context, active bool) {
+	id, err := controller.idParam(ctx)
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+
+	search, err := controller.bindSearch(ctx)
+	if err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+
+	user, err := controller.serviceFor(ctx).Update(search, &model.SystemUser{UserId: id, Active: &active})
+	if err != nil {
+		RespondError(ctx, err)
+	} else {
+		ctx.JSON(http.StatusOK, user)
+	}
+}
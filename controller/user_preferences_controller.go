@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/roksky/bootstrap-api/apperror"
+	"github.com/roksky/bootstrap-api/service"
+	"github.com/rs/zerolog/log"
+)
+
+// UserPreferencesController lets the token user read and write their preferences document:
+// GET /me/preferences, PUT /me/preferences to replace it and PATCH /me/preferences with a JSON
+// merge patch (RFC 7386).
+type UserPreferencesController struct {
+	service *service.UserPreferencesService
+}
+
+func NewUserPreferencesController(service *service.UserPreferencesService) *UserPreferencesController {
+	return &UserPreferencesController{
+		service: service,
+	}
+}
+
+func (controller *UserPreferencesController) GroupName() string {
+	return "/me"
+}
+
+func (controller *UserPreferencesController) IsAuthEnabled() bool {
+	return true
+}
+
+func (controller *UserPreferencesController) Handlers() []*HttpFunc {
+	return []*HttpFunc{
+		NewHttpFunc(GET, "/preferences", controller.Get),
+		NewHttpFunc(PUT, "/preferences", controller.Replace),
+		NewHttpFunc(PATCH, "/preferences", controller.Patch),
+	}
+}
+
+func (controller *UserPreferencesController) Get(ctx *gin.Context) {
+	log.Info().Msg("get preferences")
+
+	userId, err := tokenUserId(ctx)
+	if err != nil {
+		RespondError(ctx, err)
+		return
+	}
+
+	preferences, err := controller.service.WithContext(ctx.Request.Context()).Get(userId)
+	if err != nil {
+		RespondError(ctx, err)
+	} else {
+		ctx.JSON(http.StatusOK, preferences)
+	}
+}
+
+func (controller *UserPreferencesController) Replace(ctx *gin.Context) {
+	log.Info().Msg("replace preferences")
+
+	controller.write(ctx, (*service.UserPreferencesService).Replace)
+}
+
+func (controller *UserPreferencesController) Patch(ctx *gin.Context) {
+	log.Info().Msg("patch preferences")
+
+	controller.write(ctx, (*service.UserPreferencesService).Patch)
+}
+
+// write binds the JSON object of the request body and saves it with the given service method.
+func (controller *UserPreferencesController) write(ctx *gin.Context, save func(*service.UserPreferencesService, uuid.UUID, map[string]interface{}) (map[string]interface{}, error)) {
+	userId, err := tokenUserId(ctx)
+	if err != nil {
+		RespondError(ctx, err)
+		return
+	}
+
+	var document map[string]interface{}
+	if err = ctx.ShouldBindJSON(&document); err != nil {
+		RespondError(ctx, apperror.Invalid(err))
+		return
+	}
+	if document == nil {
+		RespondError(ctx, apperror.Validation("invalid_preferences", "preferences must be a JSON object"))
+		return
+	}
+
+	preferences, err := save(controller.service.WithContext(ctx.Request.Context()), userId, document)
+	if err != nil {
+		RespondError(ctx, err)
+	} else {
+		ctx.JSON(http.StatusOK, preferences)
+	}
+}
+
+// tokenUserId returns the id of the token user, who must be a system user.
+func tokenUserId(ctx *gin.Context) (uuid.UUID, error) {
+	tokenInfo, err := GetTokenInfo(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	userId, err := uuid.Parse(tokenInfo.GetUserID())
+	if err != nil {
+		return uuid.Nil, apperror.Forbidden("not_system_user", "token user is not a system user")
+	}
+	return userId, nil
+}
@@ -1,17 +1,19 @@
-package request
-
-import "github.com/roksky/bootstrap-api/model"
-
-type RegisterUserAndOrg struct {
-	User         *model.SystemUser
-	Organization *model.Organization
-}
-
-type CreateOrganizationRequest struct {
-	Name string `validate:"required,min=1,max=200" json:"name"`
-}
-
-type UpdateOrganizationRequest struct {
-	Id   int    `validate:"required"`
-	Name string `validate:"required,max=200,min=1" json:"name"`
-}
+package request
+
+import "github.com/roksky/bootstrap-api/model"
+
+type RegisterUserAndOrg struct {
+	User         *model.SystemUser
+	Organization *model.Organization
+}
+
+type CreateOrganizationRequest struct {
+	Name string `validate:"required,min=1,max=200" json:"name"`
+}
+
+// UpdateOrganizationRequest is the body of an organization update, whose id is the one of the
+// path, or for a bulk update the id member of the item. Absent fields are left unchanged by a
+// PATCH, and cleared by a PUT.
+type UpdateOrganizationRequest struct {
+	Name *string `validate:"omitempty,max=200,min=1" json:"name"`
+}
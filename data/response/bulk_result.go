@@ -0,0 +1,12 @@
+package response
+
+// BulkItemResult is the outcome of one item of a bulk request made in partial mode.
+// Index is the position of the item in the request, and Status the HTTP status of its own outcome.
+// Item is the written entity, Id the id of a deleted one, and Error the reason of a failure.
+type BulkItemResult[T any] struct {
+	Index  int      `json:"index"`
+	Status int      `json:"status"`
+	Item   T        `json:"item,omitempty"`
+	Id     string   `json:"id,omitempty"`
+	Error  *Problem `json:"error,omitempty"`
+}
@@ -0,0 +1,13 @@
+package response
+
+import "github.com/roksky/bootstrap-api/model"
+
+// ChangesResult lists the entities upserted and deleted since a sync token.
+// Clients store SyncToken and send it back as the since parameter to resume, and keep
+// asking while HasMore is true.
+type ChangesResult[T any] struct {
+	Upserted  []T               `json:"upserted"`
+	Deleted   []model.Tombstone `json:"deleted"`
+	SyncToken string            `json:"syncToken"`
+	HasMore   bool              `json:"hasMore"`
+}
@@ -0,0 +1,9 @@
+package response
+
+// CursorResult is a page of a keyset paginated search.
+// NextCursor is empty when there are no more items.
+type CursorResult[T any] struct {
+	PageSize   int64  `json:"pageSize"`
+	NextCursor string `json:"nextCursor"`
+	Items      []T    `json:"items"`
+}
@@ -1,6 +1,4 @@
 package response
 
-type ErrorResponse struct {
-	Code    string      `json:"code"`
-	Message interface{} `json:"message,omitempty"`
-}
+// Deprecated: errors are rendered as Problem, use it instead.
+type ErrorResponse = Problem
@@ -0,0 +1,65 @@
+package response
+
+import "encoding/json"
+
+// ProblemContentType is the media type of Problem responses.
+const ProblemContentType = "application/problem+json"
+
+// FieldProblem describes why the value of a field of the request was rejected. Field is its JSON
+// path, and Rule the rule it failed, with its parameter, e.g. "min" and "3".
+type FieldProblem struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param"`
+	Message string `json:"message"`
+}
+
+// Problem is an error response following RFC 7807. Code is a stable identifier of the error, e.g.
+// "version_conflict", and Extensions are serialized as top-level members.
+type Problem struct {
+	Type       string                 `json:"type"`
+	Title      string                 `json:"title"`
+	Status     int                    `json:"status"`
+	Code       string                 `json:"code"`
+	Detail     string                 `json:"detail,omitempty"`
+	Instance   string                 `json:"instance,omitempty"`
+	RequestId  string                 `json:"requestId,omitempty"`
+	Errors     []FieldProblem         `json:"errors,omitempty"`
+	Extensions map[string]interface{} `json:"-"`
+}
+
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type members Problem
+	data, err := json.Marshal(members(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return data, err
+	}
+	var merged map[string]interface{}
+	if err = json.Unmarshal(data, &merged); err != nil {
+		return nil, err
+	}
+	for name, value := range p.Extensions {
+		if _, reserved := merged[name]; !reserved {
+			merged[name] = value
+		}
+	}
+	return json.Marshal(merged)
+}
+
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	type members Problem
+	if err := json.Unmarshal(data, (*members)(p)); err != nil {
+		return err
+	}
+	var all map[string]interface{}
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+	for _, name := range []string{"type", "title", "status", "code", "detail", "instance", "requestId", "errors"} {
+		delete(all, name)
+	}
+	if len(all) > 0 {
+		p.Extensions = all
+	}
+	return nil
+}
@@ -0,0 +1,7 @@
+package response
+
+// StatsResult lists the groups of an aggregation. Each group holds the values of the group by
+// fields and the computed metrics, e.g. {"userRole": "owner", "count": 3}.
+type StatsResult struct {
+	Groups []map[string]interface{} `json:"groups"`
+}
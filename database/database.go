@@ -2,6 +2,7 @@ package database
 
 import (
 	"github.com/roksky/bootstrap-api/model"
+	"github.com/roksky/bootstrap-api/repository"
 	"gorm.io/gorm"
 )
 
@@ -15,7 +16,22 @@ type MyDb struct {
 }
 
 func (m *MyDb) AutoMigrate() error {
-	return m.db.AutoMigrate(getModels()...)
+	models := getModels()
+	if err := m.db.AutoMigrate(models...); err != nil {
+		return err
+	}
+	for _, item := range models {
+		if err := repository.MigrateTextSearch(m.db, item); err != nil {
+			return err
+		}
+		// historized models keep their versions in a table of their own
+		if historized, ok := item.(model.Historized); ok {
+			if err := m.db.Table(historized.HistoryTable()).AutoMigrate(&model.EntityVersion{}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 func (m *MyDb) Database() *gorm.DB {
@@ -33,5 +49,8 @@ func NewDatabase(db *gorm.DB) Database {
 func getModels() []any {
 	return []any{
 		&model.Organization{},
+		&model.AuditLog{},
+		&model.UserPreferences{},
+		&model.PurgedEntity{},
 	}
 }
@@ -2,6 +2,7 @@ package helper
 
 import (
 	"fmt"
+	"github.com/roksky/bootstrap-api/apperror"
 	"github.com/xeipuuv/gojsonschema"
 	"strings"
 )
@@ -30,3 +31,10 @@ func (e *GroupedError) Error() string {
 func (e *GroupedError) GetErrors() []gojsonschema.ResultError {
 	return e.Errors
 }
+
+// AppError reports the schema errors as the invalid fields of a validation error.
+func (e *GroupedError) AppError() *apperror.Error {
+	grouped := apperror.FromSchema(e.Errors)
+	grouped.Cause = e
+	return grouped
+}
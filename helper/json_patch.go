@@ -0,0 +1,248 @@
+package helper
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/roksky/bootstrap-api/apperror"
+)
+
+// PatchOperation is an operation of a JSON patch (RFC 6902). Value is kept raw, for a null value
+// to be told apart from a missing one.
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// JSONPatch applies a JSON patch (RFC 6902) to a copy of target. Nothing is applied when an
+// operation fails, a failed test being a conflict.
+func JSONPatch(target interface{}, operations []PatchOperation) (interface{}, error) {
+	document := deepCopy(target)
+	for _, operation := range operations {
+		var err error
+		if document, err = applyOperation(document, operation); err != nil {
+			return nil, err
+		}
+	}
+	return document, nil
+}
+
+func applyOperation(document interface{}, operation PatchOperation) (interface{}, error) {
+	path, err := parsePointer(operation.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch operation.Op {
+	case "add", "replace", "test":
+		if operation.Value == nil {
+			return nil, invalidPatch("the %s operation of %s has no value", operation.Op, operation.Path)
+		}
+		var value interface{}
+		if err = json.Unmarshal(operation.Value, &value); err != nil {
+			return nil, invalidPatch("the value of %s is not valid JSON", operation.Path)
+		}
+		switch operation.Op {
+		case "add":
+			return addValue(document, path, value)
+		case "replace":
+			if len(path) == 0 {
+				return value, nil
+			}
+			if _, err = valueAt(document, path); err != nil {
+				return nil, err
+			}
+			if document, err = removeValue(document, path); err != nil {
+				return nil, err
+			}
+			return addValue(document, path, value)
+		default:
+			current, err := valueAt(document, path)
+			if err != nil {
+				return nil, err
+			}
+			if !reflect.DeepEqual(current, value) {
+				return nil, apperror.Conflict("patch_test_failed", fmt.Sprintf("the value of %s is not the tested one", operation.Path))
+			}
+			return document, nil
+		}
+	case "remove":
+		return removeValue(document, path)
+	case "move", "copy":
+		from, err := parsePointer(operation.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err := valueAt(document, from)
+		if err != nil {
+			return nil, err
+		}
+		if operation.Op == "copy" {
+			return addValue(document, path, deepCopy(value))
+		}
+		if strings.HasPrefix(operation.Path+"/", operation.From+"/") && operation.Path != operation.From {
+			return nil, invalidPatch("%s cannot be moved into itself", operation.From)
+		}
+		if document, err = removeValue(document, from); err != nil {
+			return nil, err
+		}
+		return addValue(document, path, value)
+	default:
+		return nil, invalidPatch("unknown operation %q", operation.Op)
+	}
+}
+
+// parsePointer splits a JSON pointer (RFC 6901) into its unescaped tokens, none for the whole document.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, invalidPatch("%s is not a JSON pointer", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func valueAt(document interface{}, path []string) (interface{}, error) {
+	current := document
+	for _, token := range path {
+		switch container := current.(type) {
+		case map[string]interface{}:
+			value, ok := container[token]
+			if !ok {
+				return nil, missingMember(path)
+			}
+			current = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(container)-1, path)
+			if err != nil {
+				return nil, err
+			}
+			current = container[index]
+		default:
+			return nil, missingMember(path)
+		}
+	}
+	return current, nil
+}
+
+// addValue adds value at path, replacing the member of an object or inserting into an array.
+func addValue(document interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return changeParent(document, path, func(parent interface{}, token string) (interface{}, error) {
+		switch container := parent.(type) {
+		case map[string]interface{}:
+			container[token] = value
+			return container, nil
+		case []interface{}:
+			if token == "-" {
+				return append(container, value), nil
+			}
+			index, err := arrayIndex(token, len(container), path)
+			if err != nil {
+				return nil, err
+			}
+			container = append(container, nil)
+			copy(container[index+1:], container[index:])
+			container[index] = value
+			return container, nil
+		default:
+			return nil, missingMember(path)
+		}
+	})
+}
+
+func removeValue(document interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, invalidPatch("the whole document cannot be removed")
+	}
+	return changeParent(document, path, func(parent interface{}, token string) (interface{}, error) {
+		switch container := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := container[token]; !ok {
+				return nil, missingMember(path)
+			}
+			delete(container, token)
+			return container, nil
+		case []interface{}:
+			index, err := arrayIndex(token, len(container)-1, path)
+			if err != nil {
+				return nil, err
+			}
+			return append(container[:index], container[index+1:]...), nil
+		default:
+			return nil, missingMember(path)
+		}
+	})
+}
+
+// changeParent replaces the container holding the last token of path with the one returned by
+// change, and returns the document.
+func changeParent(document interface{}, path []string, change func(parent interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return change(document, path[0])
+	}
+	parent, err := valueAt(document, path[:1])
+	if err != nil {
+		return nil, missingMember(path)
+	}
+	changed, err := changeParent(parent, path[1:], change)
+	if err != nil {
+		return nil, err
+	}
+	switch container := document.(type) {
+	case map[string]interface{}:
+		container[path[0]] = changed
+	case []interface{}:
+		index, _ := strconv.Atoi(path[0])
+		container[index] = changed
+	}
+	return document, nil
+}
+
+// arrayIndex parses the index of an array element, from 0 to max.
+func arrayIndex(token string, max int, path []string) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index > max || (len(token) > 1 && token[0] == '0') {
+		return 0, missingMember(path)
+	}
+	return index, nil
+}
+
+func deepCopy(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(typed))
+		for name, member := range typed {
+			copied[name] = deepCopy(member)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(typed))
+		for i, element := range typed {
+			copied[i] = deepCopy(element)
+		}
+		return copied
+	default:
+		return value
+	}
+}
+
+func missingMember(path []string) error {
+	return invalidPatch("/%s does not exist", strings.Join(path, "/"))
+}
+
+func invalidPatch(format string, args ...interface{}) error {
+	return apperror.Validation("invalid_patch", fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,25 @@
+package helper
+
+// MergePatch applies a JSON merge patch (RFC 7386) to a document decoded from JSON, and returns the
+// patched document without modifying target. Members of the patch set to null are removed from the
+// target, objects are merged recursively and any other value replaces the target one.
+func MergePatch(target interface{}, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, _ := target.(map[string]interface{})
+	patched := make(map[string]interface{}, len(targetObject)+len(patchObject))
+	for name, value := range targetObject {
+		patched[name] = value
+	}
+	for name, value := range patchObject {
+		if value == nil {
+			delete(patched, name)
+			continue
+		}
+		patched[name] = MergePatch(patched[name], value)
+	}
+	return patched
+}
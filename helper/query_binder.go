@@ -0,0 +1,151 @@
+package helper
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	timeType            = reflect.TypeOf(time.Time{})
+)
+
+// BindQuery fills the fields of dst tagged with `query:"name"` from the query values.
+// A `default:"value"` tag is used when the parameter is absent. Slices accept repeated
+// parameters as well as comma separated values. Embedded structs are bound recursively.
+func BindQuery(values url.Values, dst any) error {
+	target := reflect.ValueOf(dst)
+	if target.Kind() != reflect.Pointer || target.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("query binding target must be a pointer to a struct, got %T", dst)
+	}
+	return bindQueryStruct(values, target.Elem())
+}
+
+func bindQueryStruct(values url.Values, target reflect.Value) error {
+	targetType := target.Type()
+	for i := 0; i < targetType.NumField(); i++ {
+		field := targetType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if err := bindQueryStruct(values, target.Field(i)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name := field.Tag.Get("query")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		params, ok := values[name]
+		if !ok || len(params) == 0 {
+			defaultValue, hasDefault := field.Tag.Lookup("default")
+			if !hasDefault {
+				continue
+			}
+			params = []string{defaultValue}
+		}
+
+		if err := setQueryValue(target.Field(i), params); err != nil {
+			return fmt.Errorf("invalid value for query parameter %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func setQueryValue(target reflect.Value, params []string) error {
+	if target.Kind() == reflect.Slice && target.Type().Elem().Kind() != reflect.Uint8 {
+		var parts []string
+		for _, param := range params {
+			for _, part := range strings.Split(param, ",") {
+				if part = strings.TrimSpace(part); part != "" {
+					parts = append(parts, part)
+				}
+			}
+		}
+		slice := reflect.MakeSlice(target.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setScalarValue(slice.Index(i), part); err != nil {
+				return err
+			}
+		}
+		target.Set(slice)
+		return nil
+	}
+	return setScalarValue(target, params[0])
+}
+
+func setScalarValue(target reflect.Value, param string) error {
+	if target.Kind() == reflect.Pointer {
+		if param == "" {
+			return nil
+		}
+		value := reflect.New(target.Type().Elem())
+		if err := setScalarValue(value.Elem(), param); err != nil {
+			return err
+		}
+		target.Set(value)
+		return nil
+	}
+
+	if param == "" && target.Kind() != reflect.String {
+		return nil
+	}
+
+	if target.Type() == timeType {
+		parsed, err := time.Parse(time.RFC3339, param)
+		if err != nil {
+			parsed, err = time.Parse(time.DateOnly, param)
+		}
+		if err != nil {
+			return err
+		}
+		target.Set(reflect.ValueOf(parsed))
+		return nil
+	}
+
+	if reflect.PointerTo(target.Type()).Implements(textUnmarshalerType) {
+		return target.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(param))
+	}
+
+	switch target.Kind() {
+	case reflect.String:
+		target.SetString(param)
+	case reflect.Bool:
+		value, err := strconv.ParseBool(param)
+		if err != nil {
+			return err
+		}
+		target.SetBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value, err := strconv.ParseInt(param, 10, target.Type().Bits())
+		if err != nil {
+			return err
+		}
+		target.SetInt(value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value, err := strconv.ParseUint(param, 10, target.Type().Bits())
+		if err != nil {
+			return err
+		}
+		target.SetUint(value)
+	case reflect.Float32, reflect.Float64:
+		value, err := strconv.ParseFloat(param, target.Type().Bits())
+		if err != nil {
+			return err
+		}
+		target.SetFloat(value)
+	default:
+		return fmt.Errorf("unsupported field type %s", target.Type())
+	}
+	return nil
+}
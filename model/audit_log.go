@@ -0,0 +1,56 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/roksky/bootstrap-api/types"
+	"gorm.io/gorm"
+)
+
+// AuditAction is the kind of write recorded by an AuditLog entry.
+type AuditAction string
+
+const (
+	AuditCreate  AuditAction = "create"
+	AuditUpdate  AuditAction = "update"
+	AuditDelete  AuditAction = "delete"
+	AuditRestore AuditAction = "restore"
+	AuditPurge   AuditAction = "purge"
+)
+
+// AuditLog records a write made to an entity. Before and After hold the JSON fields of the entity
+// that the write changed, the whole entity for creates and purges.
+type AuditLog struct {
+	Id             uuid.UUID   `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	Actor          string      `gorm:"type:varchar(255);index" json:"actor"`
+	OrganizationId *uuid.UUID  `gorm:"type:uuid;index;column:organization" json:"organizationId"`
+	EntityType     string      `gorm:"type:varchar(255);index:idx_audit_log_entity" json:"entityType"`
+	EntityId       string      `gorm:"type:varchar(255);index:idx_audit_log_entity" json:"entityId"`
+	Action         AuditAction `gorm:"type:varchar(32)" json:"action"`
+	Before         types.JSON  `gorm:"type:jsonb" json:"before"`
+	After          types.JSON  `gorm:"type:jsonb" json:"after"`
+	RequestId      string      `gorm:"type:varchar(255);index" json:"requestId"`
+	DateCreated    time.Time   `gorm:"index" json:"dateCreated"`
+}
+
+func (t *AuditLog) TableName() string {
+	return "audit_log"
+}
+
+func (t *AuditLog) GetId() uuid.UUID {
+	return t.Id
+}
+
+func (t *AuditLog) SetId(id uuid.UUID) {
+	t.Id = id
+}
+
+func (t *AuditLog) TenantColumn() string {
+	return "organization"
+}
+
+func (t *AuditLog) BeforeCreate(tx *gorm.DB) (err error) {
+	t.DateCreated = time.Now()
+	return
+}
@@ -6,6 +6,54 @@ import (
 	"time"
 )
 
+// Identifiable is implemented by models that expose their primary key.
+type Identifiable[K comparable] interface {
+	GetId() K
+	SetId(id K)
+}
+
+// Auditable is implemented by models that record which user created and last updated them.
+type Auditable interface {
+	SetCreatedBy(user string)
+	SetUpdatedBy(user string)
+}
+
+// Versioned is implemented by models using optimistic concurrency control.
+// The version is incremented on every update, and an update carrying a stale version is rejected.
+type Versioned interface {
+	GetVersion() int64
+	SetVersion(version int64)
+}
+
+// TenantOwned is implemented by models belonging to an organization. When tenant scoping is enabled,
+// their reads and writes are restricted to the organization of the request.
+type TenantOwned interface {
+	// TenantColumn returns the column holding the organization id.
+	TenantColumn() string
+}
+
+// ServerManaged is implemented by models having fields maintained by the server, such as their id
+// and timestamps, which the bodies of the requests must not set.
+type ServerManaged interface {
+	// ClearServerFields resets the fields maintained by the server.
+	ClearServerFields()
+}
+
+// Tombstone records the deletion of an entity for clients synchronising changes.
+type Tombstone struct {
+	Id          string    `json:"id"`
+	DateDeleted time.Time `json:"dateDeleted"`
+	DeletedBy   string    `json:"deletedBy"`
+}
+
+// Tracked is implemented by soft deletable models whose changes can be synchronised.
+type Tracked interface {
+	// ChangedAt returns the time of the last change, deletion included.
+	ChangedAt() time.Time
+	// Tombstone returns the deletion record, nil when the entity is not deleted.
+	Tombstone() *Tombstone
+}
+
 type IdentifiedModel struct {
 	Id          uuid.UUID      `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
 	DateCreated time.Time      `json:"dateCreated"`
@@ -15,9 +63,71 @@ type IdentifiedModel struct {
 	CreatedBy string `gorm:"type:varchar(255)" json:"createdBy"`
 	UpdatedBy string `gorm:"type:varchar(255)" json:"updatedBy"`
 	DeletedBy string `gorm:"type:varchar(255)" json:"deletedBy"`
+
+	// Version is incremented by every write, deletion and restoration included.
+	// It is optional on updates: when it is 0 the update is applied whatever the stored version.
+	Version int64 `gorm:"not null;default:1" json:"version"`
+}
+
+func (u *IdentifiedModel) GetId() uuid.UUID {
+	return u.Id
+}
+
+func (u *IdentifiedModel) SetId(id uuid.UUID) {
+	u.Id = id
+}
+
+// ClearServerFields resets the id, the timestamps and the users of the model, keeping its version
+// which an update gives to be applied to that version only. The version of a created model is reset
+// by the controller, the model starting at the first version.
+func (u *IdentifiedModel) ClearServerFields() {
+	u.Id = uuid.Nil
+	u.DateCreated = time.Time{}
+	u.DateUpdated = time.Time{}
+	u.DateDeleted = gorm.DeletedAt{}
+	u.CreatedBy = ""
+	u.UpdatedBy = ""
+	u.DeletedBy = ""
+}
+
+func (u *IdentifiedModel) SetCreatedBy(user string) {
+	u.CreatedBy = user
+}
+
+func (u *IdentifiedModel) SetUpdatedBy(user string) {
+	u.UpdatedBy = user
+}
+
+func (u *IdentifiedModel) GetVersion() int64 {
+	return u.Version
+}
+
+func (u *IdentifiedModel) SetVersion(version int64) {
+	u.Version = version
+}
+
+func (u *IdentifiedModel) ChangedAt() time.Time {
+	if u.DateDeleted.Valid {
+		return u.DateDeleted.Time
+	}
+	return u.DateUpdated
+}
+
+func (u *IdentifiedModel) Tombstone() *Tombstone {
+	if !u.DateDeleted.Valid {
+		return nil
+	}
+	return &Tombstone{
+		Id:          u.Id.String(),
+		DateDeleted: u.DateDeleted.Time,
+		DeletedBy:   u.DeletedBy,
+	}
 }
 
 func (u *IdentifiedModel) BeforeCreate(tx *gorm.DB) (err error) {
+	if u.Version == 0 {
+		u.Version = 1
+	}
 	u.DateCreated = time.Now()
 	u.DateUpdated = time.Now()
 	return
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"github.com/roksky/bootstrap-api/types"
+	"gorm.io/gorm"
+)
+
+// Historized is implemented by versioned models keeping the snapshot of every version in a history table.
+type Historized interface {
+	// HistoryTable returns the name of the history table, e.g. organization_versions.
+	HistoryTable() string
+}
+
+// EntityVersion is the snapshot of an entity as written with a given version.
+type EntityVersion struct {
+	EntityId    string      `gorm:"primaryKey;type:varchar(255)" json:"entityId"`
+	Version     int64       `gorm:"primaryKey;autoIncrement:false" json:"version"`
+	Action      AuditAction `gorm:"type:varchar(32)" json:"action"`
+	Snapshot    types.JSON  `gorm:"type:jsonb" json:"snapshot"`
+	Actor       string      `gorm:"type:varchar(255)" json:"actor"`
+	DateCreated time.Time   `json:"dateCreated"`
+}
+
+func (t *EntityVersion) BeforeCreate(tx *gorm.DB) (err error) {
+	t.DateCreated = time.Now()
+	return
+}
@@ -4,5 +4,15 @@ package model
 // Users should extend this struct to provide additional details.
 type Organization struct {
 	IdentifiedModel
-	Name string `gorm:"type:varchar(255)" json:"name"`
+	Name string `gorm:"type:varchar(255)" json:"name" search:"A"`
+}
+
+// TenantColumn makes an organization the tenant of itself.
+func (o *Organization) TenantColumn() string {
+	return "id"
+}
+
+// HistoryTable keeps the versions of organizations in organization_versions.
+func (o *Organization) HistoryTable() string {
+	return "organization_versions"
 }
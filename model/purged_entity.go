@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PurgedEntity keeps the tombstone of a tracked entity once purged, for clients synchronising
+// changes to learn that it is gone.
+type PurgedEntity struct {
+	EntityType     string     `gorm:"primaryKey;type:varchar(255)" json:"entityType"`
+	EntityId       string     `gorm:"primaryKey;type:varchar(255)" json:"entityId"`
+	OrganizationId *uuid.UUID `gorm:"type:uuid;index;column:organization" json:"organizationId"`
+	DateDeleted    time.Time  `gorm:"index" json:"dateDeleted"`
+	DeletedBy      string     `gorm:"type:varchar(255)" json:"deletedBy"`
+}
+
+func (t *PurgedEntity) Tombstone() *Tombstone {
+	return &Tombstone{
+		Id:          t.EntityId,
+		DateDeleted: t.DateDeleted,
+		DeletedBy:   t.DeletedBy,
+	}
+}
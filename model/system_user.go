@@ -13,12 +13,31 @@ type SystemUser struct {
 	DateUpdated         time.Time      `json:"dateUpdated"`
 	DateDeleted         gorm.DeletedAt `gorm:"index" json:"dateDeleted"`
 	PrimaryOrganization uuid.UUID      `json:"primaryOrganization"`
+	// Active is false once an administrator deactivated the user. It is a pointer so that updates
+	// leaving it nil do not change it.
+	Active *bool `gorm:"not null;default:true" json:"active"`
 }
 
 func (t *SystemUser) TableName() string {
 	return "system_users"
 }
 
+func (t *SystemUser) GetId() uuid.UUID {
+	return t.UserId
+}
+
+func (t *SystemUser) SetId(id uuid.UUID) {
+	t.UserId = id
+}
+
+// ClearServerFields resets the id and the timestamps of the user.
+func (t *SystemUser) ClearServerFields() {
+	t.UserId = uuid.Nil
+	t.DateCreated = time.Time{}
+	t.DateUpdated = time.Time{}
+	t.DateDeleted = gorm.DeletedAt{}
+}
+
 func (t *SystemUser) BeforeCreate(tx *gorm.DB) (err error) {
 	t.DateCreated = time.Now()
 	t.DateUpdated = time.Now()
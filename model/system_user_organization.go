@@ -12,3 +12,7 @@ type SystemUserOrganization struct {
 	Organization   Organization   `json:"organization"`
 	UserRole       SystemUserRole `gorm:"index" json:"userRole"`
 }
+
+func (t *SystemUserOrganization) TenantColumn() string {
+	return "organization"
+}
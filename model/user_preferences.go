@@ -1,10 +1,13 @@
 package model
 
-import "github.com/google/uuid"
+import (
+	"github.com/google/uuid"
+	"github.com/roksky/bootstrap-api/types"
+)
 
 type UserPreferences struct {
 	IdentifiedModel
-	SystemUserId uuid.UUID              `gorm:"index;column:system_user" json:"-"`
-	SystemUser   SystemUser             `json:"systemUser"`
-	Preferences  map[string]interface{} `gorm:"type:json"`
+	SystemUserId uuid.UUID     `gorm:"uniqueIndex;column:system_user" json:"-"`
+	SystemUser   SystemUser    `json:"systemUser"`
+	Preferences  types.JSONMap `gorm:"type:jsonb" json:"preferences"`
 }
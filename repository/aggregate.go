@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/roksky/bootstrap-api/apperror"
+	"gorm.io/gorm"
+)
+
+// Aggregate functions supported by AggregateRequest metrics.
+const (
+	MetricCount = "count"
+	MetricSum   = "sum"
+	MetricAvg   = "avg"
+	MetricMin   = "min"
+	MetricMax   = "max"
+)
+
+// Granularities truncating the dates grouped on, e.g. "dateCreated:month".
+var aggregateGranularities = []string{"hour", "day", "week", "month", "quarter", "year"}
+
+// InvalidAggregateError is returned when a group by field or a metric of an AggregateRequest is not allowed.
+type InvalidAggregateError struct {
+	Expression string
+	Reason     string
+	Allowed    []string
+}
+
+func (e *InvalidAggregateError) Error() string {
+	if len(e.Allowed) == 0 {
+		return fmt.Sprintf("invalid aggregate %q: %s", e.Expression, e.Reason)
+	}
+	return fmt.Sprintf("invalid aggregate %q: %s, allowed values are %s", e.Expression, e.Reason, strings.Join(e.Allowed, ", "))
+}
+
+func (e *InvalidAggregateError) AppError() *apperror.Error {
+	invalid := apperror.Validation("invalid_aggregate", e.Error())
+	if len(e.Allowed) > 0 {
+		invalid = invalid.With("allowedValues", e.Allowed)
+	}
+	return invalid
+}
+
+// AggregateRequest describes statistics computed over the entities matching a search.
+// GroupBy takes "field" or "field:granularity", e.g. "dateCreated:month", and Metrics "count" or
+// "function:field", e.g. "sum:version".
+type AggregateRequest struct {
+	GroupBy []string
+	Metrics []string
+}
+
+// AggregateRow is one group of an aggregation. It holds the value of each group by field under
+// the field name, and each metric under its name: "count", or the function followed by the field,
+// e.g. "sumVersion".
+type AggregateRow = map[string]interface{}
+
+// aggregateSelect is the SELECT expression of a group or a metric and its alias.
+type aggregateSelect struct {
+	expression string
+	alias      string
+}
+
+// Aggregate computes the metrics of request over the entities of T matching searchParams, per
+// group. Groups are ordered by their group by values, and limited to the page size of the search
+// when it is set.
+func (e *GormRepository[T, K, S]) Aggregate(tx *gorm.DB, searchParams *S, request *AggregateRequest) ([]AggregateRow, error) {
+	db := e.getDB(tx)
+	registry := SortRegistryOf[T](db, searchParams)
+	metrics := request.Metrics
+	if len(metrics) == 0 {
+		metrics = []string{MetricCount}
+	}
+
+	selects := make([]aggregateSelect, 0, len(request.GroupBy)+len(metrics))
+	for _, groupBy := range request.GroupBy {
+		group, err := aggregateGroup(db, registry, groupBy)
+		if err != nil {
+			return nil, err
+		}
+		selects = append(selects, group)
+	}
+	for _, metric := range metrics {
+		selected, err := aggregateMetric(db, registry, metric)
+		if err != nil {
+			return nil, err
+		}
+		selects = append(selects, selected)
+	}
+
+	expressions := make([]string, 0, len(selects))
+	for _, selected := range selects {
+		expressions = append(expressions, selected.expression+" AS "+db.Statement.Quote(selected.alias))
+	}
+	db = e.applyFilters(db.Model(new(T)), searchParams).Select(strings.Join(expressions, ", "))
+	if len(request.GroupBy) > 0 {
+		positions := make([]string, 0, len(request.GroupBy))
+		for i := range request.GroupBy {
+			positions = append(positions, fmt.Sprint(i+1))
+		}
+		db = db.Group(strings.Join(positions, ", ")).Order(strings.Join(positions, ", "))
+	}
+	if pageRequest := pageRequestOf(searchParams); pageRequest.PageSize > 0 {
+		db = db.Limit(pageRequest.Limit())
+	}
+
+	var rows []AggregateRow
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func aggregateGroup(db *gorm.DB, registry *SortRegistry, groupBy string) (aggregateSelect, error) {
+	name, granularity, truncated := strings.Cut(strings.TrimSpace(groupBy), ":")
+	column, ok := registry.Column(name)
+	if !ok {
+		return aggregateSelect{}, &InvalidAggregateError{Expression: groupBy, Reason: "unknown field", Allowed: registry.AllowedFields()}
+	}
+	if !truncated {
+		return aggregateSelect{expression: db.Statement.Quote(column), alias: name}, nil
+	}
+	for _, allowed := range aggregateGranularities {
+		if granularity == allowed {
+			return aggregateSelect{
+				expression: fmt.Sprintf("date_trunc('%s', %s)", granularity, db.Statement.Quote(column)),
+				alias:      name,
+			}, nil
+		}
+	}
+	return aggregateSelect{}, &InvalidAggregateError{Expression: groupBy, Reason: "unknown granularity", Allowed: aggregateGranularities}
+}
+
+func aggregateMetric(db *gorm.DB, registry *SortRegistry, metric string) (aggregateSelect, error) {
+	function, name, _ := strings.Cut(strings.TrimSpace(metric), ":")
+	switch function {
+	case MetricCount:
+		if name == "" {
+			return aggregateSelect{expression: "count(*)", alias: MetricCount}, nil
+		}
+	case MetricSum, MetricAvg, MetricMin, MetricMax:
+	default:
+		return aggregateSelect{}, &InvalidAggregateError{Expression: metric, Reason: "unknown function",
+			Allowed: []string{MetricCount, MetricSum, MetricAvg, MetricMin, MetricMax}}
+	}
+
+	column, ok := registry.Column(name)
+	if !ok {
+		return aggregateSelect{}, &InvalidAggregateError{Expression: metric, Reason: "unknown field", Allowed: registry.AllowedFields()}
+	}
+	runes := []rune(name)
+	runes[0] = unicode.ToUpper(runes[0])
+	return aggregateSelect{
+		expression: fmt.Sprintf("%s(%s)", function, db.Statement.Quote(column)),
+		alias:      function + string(runes),
+	}, nil
+}
@@ -0,0 +1,250 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/google/uuid"
+	"github.com/roksky/bootstrap-api/model"
+	"github.com/roksky/bootstrap-api/types"
+	"gorm.io/gorm"
+)
+
+type actorKey struct{}
+
+type requestIdKey struct{}
+
+// WithActor returns a copy of ctx carrying the user performing the request, who is recorded in
+// the audit log and as DeletedBy.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the user set by WithActor, empty when there is none.
+func ActorFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// WithRequestId returns a copy of ctx carrying the id of the request, recorded in the audit log.
+func WithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdKey{}, requestId)
+}
+
+// RequestIdFromContext returns the request id set by WithRequestId, empty when there is none.
+func RequestIdFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestId, _ := ctx.Value(requestIdKey{}).(string)
+	return requestId
+}
+
+const auditLogPlugin = "bootstrap:audit_log"
+
+// auditLog is the gorm plugin marking a database whose repository writes are audited.
+type auditLog struct{}
+
+func (auditLog) Name() string {
+	return auditLogPlugin
+}
+
+func (auditLog) Initialize(*gorm.DB) error {
+	return nil
+}
+
+// EnableAuditLog records every write made by the repositories of db in the audit_log table, see
+// model.AuditLog. The entries are written in the transaction of the write, along with the actor and
+// the request id of its context, a write being rolled back when its entries cannot be written.
+func EnableAuditLog(db *gorm.DB) error {
+	return db.Use(auditLog{})
+}
+
+func auditEnabled(db *gorm.DB) bool {
+	_, ok := db.Config.Plugins[auditLogPlugin]
+	return ok
+}
+
+// NewAuditLog builds the audit entry of a write made to the entity of type T with the given id, for
+// writes made outside of a repository. before is nil for a create, and after is nil for a purge.
+// The entry carries the actor and the request id of the context of db.
+func NewAuditLog[T any](db *gorm.DB, action model.AuditAction, entityId string, before *T, after *T) (*model.AuditLog, error) {
+	ctx := db.Statement.Context
+	beforeFields, err := jsonFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := jsonFields(after)
+	if err != nil {
+		return nil, err
+	}
+	if beforeFields != nil && afterFields != nil {
+		for name, value := range beforeFields {
+			if bytes.Equal(value, afterFields[name]) {
+				delete(beforeFields, name)
+				delete(afterFields, name)
+			}
+		}
+	}
+
+	entry := &model.AuditLog{
+		Actor:      ActorFromContext(ctx),
+		EntityType: entityTypeOf[T](),
+		EntityId:   entityId,
+		Action:     action,
+		RequestId:  RequestIdFromContext(ctx),
+	}
+	if entry.Before, err = marshalFields(beforeFields); err != nil {
+		return nil, err
+	}
+	if entry.After, err = marshalFields(afterFields); err != nil {
+		return nil, err
+	}
+
+	if organizationId, ok := TenantFromContext(ctx); ok {
+		entry.OrganizationId = &organizationId
+	} else if after != nil {
+		entry.OrganizationId = tenantOf(db, after)
+	} else {
+		entry.OrganizationId = tenantOf(db, before)
+	}
+	return entry, nil
+}
+
+// entityTypeOf returns the name under which the entities of type T are recorded, e.g. Organization.
+func entityTypeOf[T any]() string {
+	return reflect.TypeOf(new(T)).Elem().Name()
+}
+
+// snapshot loads the entities with the given ids before a write, when db is audited.
+func (e *GormRepository[T, K, S]) snapshot(db *gorm.DB, ids []K) (map[K]*T, error) {
+	if !auditEnabled(db) {
+		return nil, nil
+	}
+	return e.load(db, ids)
+}
+
+// load returns the entities with the given ids by id, deleted ones included.
+func (e *GormRepository[T, K, S]) load(db *gorm.DB, ids []K) (map[K]*T, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var entities []*T
+	result := db.Session(&gorm.Session{NewDB: true}).Unscoped().Where(e.idIn(ids)).Find(&entities)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	loaded := make(map[K]*T, len(entities))
+	for _, entity := range entities {
+		loaded[e.idOf(entity)] = entity
+	}
+	return loaded, nil
+}
+
+// recordWrite records a write made to the entities with the given ids, from their before snapshot
+// to their current state: in the audit log when db is audited, and in the history table of T when
+// it keeps one.
+func (e *GormRepository[T, K, S]) recordWrite(db *gorm.DB, action model.AuditAction, ids []K, before map[K]*T) error {
+	historyTable, historized := historyTableOf[T]()
+	if !auditEnabled(db) && !historized {
+		return nil
+	}
+	readDB := db
+	if action == model.AuditCreate {
+		// the created entities passed the tenant check of their creation, and some of them, such
+		// as organizations, are not created in the scope of the organization of the context
+		readDB = db.WithContext(WithoutTenantScope(db.Statement.Context))
+	}
+	after, err := e.load(readDB, ids)
+	if err != nil {
+		return err
+	}
+	if auditEnabled(db) {
+		if err = e.audit(db, action, ids, before, after); err != nil {
+			return err
+		}
+	}
+	if historized {
+		return e.recordVersions(db, historyTable, action, ids, after)
+	}
+	return nil
+}
+
+func (e *GormRepository[T, K, S]) audit(db *gorm.DB, action model.AuditAction, ids []K, before map[K]*T, after map[K]*T) error {
+	ctx := db.Statement.Context
+	entries := make([]*model.AuditLog, 0, len(ids))
+	for _, id := range ids {
+		if before[id] == nil && after[id] == nil {
+			continue
+		}
+		entry, err := NewAuditLog(db, action, fmt.Sprint(id), before[id], after[id])
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	// the entries carry their organization, they are not subject to the tenant of the request
+	return db.Session(&gorm.Session{NewDB: true}).WithContext(WithoutTenantScope(ctx)).Create(&entries).Error
+}
+
+func jsonFields[T any](entity *T) (map[string]json.RawMessage, error) {
+	if entity == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func marshalFields(fields map[string]json.RawMessage) (types.JSON, error) {
+	if fields == nil {
+		return nil, nil
+	}
+	return json.Marshal(fields)
+}
+
+// tenantOf returns the organization of a tenant-owned entity, nil for other entities.
+func tenantOf[T any](db *gorm.DB, entity *T) *uuid.UUID {
+	if entity == nil {
+		return nil
+	}
+	owned, ok := any(entity).(model.TenantOwned)
+	if !ok {
+		return nil
+	}
+	entitySchema, err := schemaOf(db, entity)
+	if err != nil {
+		return nil
+	}
+	field := entitySchema.LookUpField(owned.TenantColumn())
+	if field == nil {
+		return nil
+	}
+	value, isZero := field.ValueOf(db.Statement.Context, reflect.ValueOf(entity).Elem())
+	if isZero {
+		return nil
+	}
+	switch organizationId := value.(type) {
+	case uuid.UUID:
+		return &organizationId
+	case *uuid.UUID:
+		return organizationId
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/roksky/bootstrap-api/model"
+	"gorm.io/gorm"
+)
+
+type AuditLogRepository struct {
+	*GormRepository[model.AuditLog, uuid.UUID, AuditLogSearch]
+}
+
+func NewAuditLogRepository(Db *gorm.DB) BaseRepository[model.AuditLog, uuid.UUID, AuditLogSearch] {
+	return &AuditLogRepository{
+		GormRepository: NewGormRepository[model.AuditLog, uuid.UUID, AuditLogSearch](Db, nil),
+	}
+}
+
+// AuditLogSearch selects audit entries by entity, organization, actor, action or request.
+type AuditLogSearch struct {
+	PageRequest
+	EntityType     string            `query:"entityType" filter:"column=entity_type"`
+	EntityId       string            `query:"entityId" filter:"column=entity_id"`
+	OrganizationId *uuid.UUID        `query:"organizationId" filter:"column=organization"`
+	Actor          string            `query:"actor" filter:"column=actor"`
+	Action         model.AuditAction `query:"action" filter:"column=action"`
+	RequestId      string            `query:"requestId" filter:"column=request_id"`
+	DateCreated    []time.Time       `query:"dateCreated" filter:"column=date_created,op=between"`
+}
+
+var auditLogSortFields = NewSortRegistry(map[string]string{
+	"dateCreated": "date_created",
+	"actor":       "actor",
+	"action":      "action",
+	"entityType":  "entity_type",
+})
+
+func (s *AuditLogSearch) SortRegistry() *SortRegistry {
+	return auditLogSortFields
+}
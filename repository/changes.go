@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/roksky/bootstrap-api/apperror"
+	"github.com/roksky/bootstrap-api/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidSyncToken is returned when the since parameter of a changes feed is neither a sync token nor a timestamp.
+var ErrInvalidSyncToken = apperror.Validation("invalid_sync_token", "invalid sync token, expected a sync token or an RFC 3339 timestamp")
+
+// ChangeSet is a page of the changes made to entities since a sync token.
+// Changes are ordered by change time, then id, and SyncToken resumes after the last of them.
+type ChangeSet[T any] struct {
+	Upserted  []*T
+	Deleted   []model.Tombstone
+	SyncToken string
+	HasMore   bool
+}
+
+// syncToken is the decoded form of a sync token: the change time and id of the last change returned.
+type syncToken struct {
+	ChangedAt time.Time       `json:"t"`
+	Id        json.RawMessage `json:"id,omitempty"`
+}
+
+// changedAtExpression is the time of the last change of a row, its deletion included.
+func changedAtExpression() clause.Expr {
+	return clause.Expr{
+		SQL:  "COALESCE(?, ?)",
+		Vars: []interface{}{filterColumn(deletedAtColumn), filterColumn(updatedAtColumn)},
+	}
+}
+
+func encodeSyncToken(changedAt time.Time, id any) (string, error) {
+	rawId, err := json.Marshal(id)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(syncToken{ChangedAt: changedAt, Id: rawId})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeSyncToken accepts a token returned by a previous change set, or a plain RFC 3339 timestamp.
+func decodeSyncToken[K comparable](since string) (time.Time, *K, error) {
+	if changedAt, err := time.Parse(time.RFC3339Nano, since); err == nil {
+		return changedAt, nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(since)
+	if err != nil {
+		return time.Time{}, nil, ErrInvalidSyncToken
+	}
+	var token syncToken
+	if err = json.Unmarshal(data, &token); err != nil {
+		return time.Time{}, nil, ErrInvalidSyncToken
+	}
+	if len(token.Id) == 0 {
+		return token.ChangedAt, nil, nil
+	}
+	id := new(K)
+	if err = json.Unmarshal(token.Id, id); err != nil {
+		return time.Time{}, nil, ErrInvalidSyncToken
+	}
+	return token.ChangedAt, id, nil
+}
+
+// afterChange restricts db to the rows changed after the given change time and id.
+func afterChange[K comparable](db *gorm.DB, changedAt time.Time, id *K, idColumn clause.Column) *gorm.DB {
+	if id == nil {
+		return db.Where(clause.Gt{Column: changedAtExpression(), Value: changedAt})
+	}
+	return db.Where(clause.Or(
+		clause.Gt{Column: changedAtExpression(), Value: changedAt},
+		clause.And(
+			clause.Eq{Column: changedAtExpression(), Value: changedAt},
+			clause.Gt{Column: idColumn, Value: *id},
+		),
+	))
+}
+
+// recordPurge keeps the tombstones of the purged entities of a tracked type, see model.PurgedEntity.
+func (e *GormRepository[T, K, S]) recordPurge(db *gorm.DB, purged map[K]*T) error {
+	if _, tracked := any(new(T)).(model.Tracked); !tracked || len(purged) == 0 {
+		return nil
+	}
+	ctx := db.Statement.Context
+	tombstones := make([]*model.PurgedEntity, 0, len(purged))
+	for id, entity := range purged {
+		tombstones = append(tombstones, &model.PurgedEntity{
+			EntityType:     entityTypeOf[T](),
+			EntityId:       fmt.Sprint(id),
+			OrganizationId: tenantOf(db, entity),
+			DateDeleted:    time.Now(),
+			DeletedBy:      ActorFromContext(ctx),
+		})
+	}
+	return db.Session(&gorm.Session{NewDB: true}).WithContext(WithoutTenantScope(ctx)).
+		Clauses(clause.OnConflict{UpdateAll: true}).Create(&tombstones).Error
+}
+
+// purgedAfter returns the tombstones of the entities of type T purged after the given change time
+// and id, restricted to the organization of the context for tenant-owned entities.
+func (e *GormRepository[T, K, S]) purgedAfter(db *gorm.DB, changedAt time.Time, afterId *K, limit int) ([]*model.PurgedEntity, error) {
+	ctx := db.Statement.Context
+	db = db.Session(&gorm.Session{NewDB: true}).WithContext(WithoutTenantScope(ctx)).
+		Where(clause.Eq{Column: clause.Column{Name: "entity_type"}, Value: entityTypeOf[T]()})
+	_, owned := any(new(T)).(model.TenantOwned)
+	if organizationId, ok := TenantFromContext(ctx); ok && owned {
+		db = db.Where(clause.Eq{Column: clause.Column{Name: "organization"}, Value: organizationId})
+	}
+	dateDeleted := clause.Column{Name: "date_deleted"}
+	entityId := clause.Column{Name: "entity_id"}
+	if afterId == nil {
+		db = db.Where(clause.Gt{Column: dateDeleted, Value: changedAt})
+	} else {
+		db = db.Where(clause.Or(
+			clause.Gt{Column: dateDeleted, Value: changedAt},
+			clause.And(clause.Eq{Column: dateDeleted, Value: changedAt}, clause.Gt{Column: entityId, Value: fmt.Sprint(*afterId)}),
+		))
+	}
+
+	var purged []*model.PurgedEntity
+	result := db.Order(clause.OrderBy{Columns: []clause.OrderByColumn{{Column: dateDeleted}, {Column: entityId}}}).
+		Limit(limit).Find(&purged)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return purged, nil
+}
+
+// change is an entry of a changes feed: an upserted or deleted entity, or the tombstone of a purged one.
+type change[T any] struct {
+	changedAt time.Time
+	id        string
+	entity    *T
+	purged    *model.PurgedEntity
+}
+
+// mergeChanges orders the changes of the entities and of the purged entities by change time, then id.
+func mergeChanges[T any, K comparable](entities []*T, purged []*model.PurgedEntity, idOf func(*T) K) []change[T] {
+	changes := make([]change[T], 0, len(entities)+len(purged))
+	for _, entity := range entities {
+		changes = append(changes, change[T]{changedAt: any(entity).(model.Tracked).ChangedAt(), id: fmt.Sprint(idOf(entity)), entity: entity})
+	}
+	for _, tombstone := range purged {
+		changes = append(changes, change[T]{changedAt: tombstone.DateDeleted, id: tombstone.EntityId, purged: tombstone})
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		if !changes[i].changedAt.Equal(changes[j].changedAt) {
+			return changes[i].changedAt.Before(changes[j].changedAt)
+		}
+		return changes[i].id < changes[j].id
+	})
+	return changes
+}
+
+// parseEntityId converts the id of a purged entity back to K, for its sync token.
+func parseEntityId[K comparable](value string) (K, error) {
+	id := new(K)
+	if err := json.Unmarshal([]byte(strconv.Quote(value)), id); err != nil {
+		if err = json.Unmarshal([]byte(value), id); err != nil {
+			return *id, err
+		}
+	}
+	return *id, nil
+}
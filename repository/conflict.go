@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// uniqueViolation is the SQLSTATE of the statements violating a unique constraint.
+const uniqueViolation = "23505"
+
+// IsUniqueViolation tells whether err comes from a statement violating a unique constraint, e.g. a
+// concurrent insert of the same key.
+func IsUniqueViolation(err error) bool {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+	var stateful interface{ SQLState() string }
+	return errors.As(err, &stateful) && stateful.SQLState() == uniqueViolation
+}
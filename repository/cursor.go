@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/roksky/bootstrap-api/apperror"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ErrInvalidCursor is returned when a cursor cannot be decoded or does not match the search ordering.
+var ErrInvalidCursor = apperror.Validation("invalid_cursor", "invalid cursor")
+
+// SortField is one column of an ORDER BY clause.
+type SortField struct {
+	Column string
+	Desc   bool
+}
+
+// cursorToken is the decoded form of a cursor: the sort values of the last item of a page.
+type cursorToken struct {
+	Values []json.RawMessage `json:"v"`
+}
+
+// keyset pages through the rows of T ordered by the sort fields followed by the id tiebreaker.
+type keyset struct {
+	schema *schema.Schema
+	fields []SortField
+}
+
+func newKeyset(db *gorm.DB, model any, sortFields []SortField, idColumn string) (*keyset, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+
+	fields := make([]SortField, 0, len(sortFields)+1)
+	for _, sortField := range sortFields {
+		if sortField.Column == idColumn {
+			continue
+		}
+		fields = append(fields, sortField)
+	}
+	fields = append(fields, SortField{Column: idColumn})
+
+	for _, field := range fields {
+		if stmt.Schema.LookUpField(field.Column) == nil {
+			return nil, fmt.Errorf("cannot paginate on unknown column %s", field.Column)
+		}
+	}
+	return &keyset{schema: stmt.Schema, fields: fields}, nil
+}
+
+// order adds the keyset ordering to db.
+func (k *keyset) order(db *gorm.DB) *gorm.DB {
+	for _, field := range k.fields {
+		db = db.Order(clause.OrderByColumn{Column: filterColumn(field.Column), Desc: field.Desc})
+	}
+	return db
+}
+
+// after restricts db to the rows following the cursor.
+func (k *keyset) after(db *gorm.DB, cursor string) (*gorm.DB, error) {
+	values, err := k.decode(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	// (a > x) OR (a = x AND b > y) OR (a = x AND b = y AND id > z), with < for descending columns
+	branches := make([]clause.Expression, 0, len(k.fields))
+	for i, field := range k.fields {
+		conditions := make([]clause.Expression, 0, i+1)
+		for j := 0; j < i; j++ {
+			conditions = append(conditions, clause.Eq{Column: filterColumn(k.fields[j].Column), Value: values[j]})
+		}
+		if field.Desc {
+			conditions = append(conditions, clause.Lt{Column: filterColumn(field.Column), Value: values[i]})
+		} else {
+			conditions = append(conditions, clause.Gt{Column: filterColumn(field.Column), Value: values[i]})
+		}
+		branches = append(branches, clause.And(conditions...))
+	}
+	return db.Where(clause.Or(branches...)), nil
+}
+
+// encode returns the cursor pointing after item.
+func (k *keyset) encode(item any) (string, error) {
+	itemValue := reflect.Indirect(reflect.ValueOf(item))
+	token := cursorToken{Values: make([]json.RawMessage, len(k.fields))}
+	for i, field := range k.fields {
+		value, _ := k.schema.LookUpField(field.Column).ValueOf(context.Background(), itemValue)
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		token.Values[i] = raw
+	}
+
+	data, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func (k *keyset) decode(cursor string) ([]interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var token cursorToken
+	if err = json.Unmarshal(data, &token); err != nil || len(token.Values) != len(k.fields) {
+		return nil, ErrInvalidCursor
+	}
+
+	values := make([]interface{}, len(k.fields))
+	for i, field := range k.fields {
+		value := reflect.New(k.schema.LookUpField(field.Column).FieldType)
+		if err = json.Unmarshal(token.Values[i], value.Interface()); err != nil {
+			return nil, ErrInvalidCursor
+		}
+		values[i] = value.Elem().Interface()
+	}
+	return values, nil
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/roksky/bootstrap-api/apperror"
+	"gorm.io/gorm"
+)
+
+// InvalidFieldError is returned when a fields parameter names a field that the entity does not have.
+type InvalidFieldError struct {
+	Field   string
+	Allowed []string
+}
+
+func (e *InvalidFieldError) Error() string {
+	return fmt.Sprintf("unknown field %q, allowed fields are %s", e.Field, strings.Join(e.Allowed, ", "))
+}
+
+func (e *InvalidFieldError) AppError() *apperror.Error {
+	return apperror.Validation("invalid_fields", e.Error()).With("allowedFields", e.Allowed)
+}
+
+// requestedFields splits the fields parameter of searchParams, nil when every field is requested.
+func requestedFields[S any](searchParams *S) []string {
+	var fields []string
+	for _, field := range strings.Split(pageRequestOf(searchParams).Fields, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// ProjectedFields returns the JSON fields of the entities to send back for searchParams: the fields
+// requested by its fields parameter and the relations it includes. It returns nil when the fields
+// parameter is empty, meaning that entities are sent whole.
+func ProjectedFields[S any](searchParams *S) []string {
+	fields := requestedFields(searchParams)
+	if fields == nil {
+		return nil
+	}
+	for _, relation := range strings.Split(pageRequestOf(searchParams).Include, ",") {
+		root, _, _ := strings.Cut(strings.TrimSpace(relation), ".")
+		if root != "" {
+			fields = append(fields, root)
+		}
+	}
+	return fields
+}
+
+// selectFields restricts the columns read by db to those of the fields requested by searchParams,
+// along with the primary key, the given columns and the keys of the included relations.
+func (e *GormRepository[T, K, S]) selectFields(db *gorm.DB, searchParams *S, columns ...string) (*gorm.DB, error) {
+	fields := requestedFields(searchParams)
+	if fields == nil {
+		return db, nil
+	}
+
+	modelColumns := ModelColumns[T](db)
+	selected := map[string]bool{}
+	add := func(column string) {
+		if !selected[column] {
+			selected[column] = true
+			columns = append(columns, column)
+		}
+	}
+	for _, column := range columns {
+		selected[column] = true
+	}
+	add(e.IdColumn)
+
+	for _, field := range fields {
+		column, ok := modelColumns[field]
+		if !ok {
+			allowed := make([]string, 0, len(modelColumns))
+			for name := range modelColumns {
+				allowed = append(allowed, name)
+			}
+			sort.Strings(allowed)
+			return nil, &InvalidFieldError{Field: field, Allowed: allowed}
+		}
+		add(column)
+	}
+
+	paths, err := IncludeRegistryOf(searchParams).Parse(pageRequestOf(searchParams).Include)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) > 0 {
+		modelSchema, err := schemaOf(db, new(T))
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			root, _, _ := strings.Cut(path, ".")
+			relation, ok := modelSchema.Relationships.Relations[root]
+			if !ok {
+				continue
+			}
+			for _, reference := range relation.References {
+				if reference.OwnPrimaryKey {
+					add(reference.PrimaryKey.DBName)
+				} else if reference.ForeignKey.Schema == modelSchema {
+					add(reference.ForeignKey.DBName)
+				}
+			}
+		}
+	}
+	return db.Select(columns), nil
+}
@@ -0,0 +1,663 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/roksky/bootstrap-api/apperror"
+	"github.com/roksky/bootstrap-api/helper"
+	"github.com/roksky/bootstrap-api/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ErrNotFound is returned when the requested entity does not exist.
+var ErrNotFound = apperror.NotFound("not_found", "entity is not found")
+
+// ErrUnsupported is returned for an operation that the repository or service does not implement,
+// see the optional interfaces of BaseRepository.
+var ErrUnsupported = apperror.NotFound("operation_unsupported", "operation is not supported")
+
+// VersionConflictError is returned when an update carries a version that is not the stored one,
+// meaning that the entity was modified since the client read it.
+type VersionConflictError struct {
+	CurrentVersion int64
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("entity was modified concurrently, current version is %d", e.CurrentVersion)
+}
+
+func (e *VersionConflictError) AppError() *apperror.Error {
+	return apperror.Conflict("version_conflict", e.Error()).With("currentVersion", e.CurrentVersion)
+}
+
+// Columns of model.IdentifiedModel used by the repository.
+const (
+	versionColumn   = "version"
+	createdAtColumn = "date_created"
+	createdByColumn = "created_by"
+	updatedAtColumn = "date_updated"
+	deletedAtColumn = "date_deleted"
+	deletedByColumn = "deleted_by"
+)
+
+// FilterApplier plugs entity specific filtering into a GormRepository, on top of the
+// filters declared with `filter` tags on the search struct.
+// ApplyFilters is used by Search, Count and Deleted so that all of them filter the same way.
+type FilterApplier[S any] interface {
+	ApplyFilters(db *gorm.DB, searchParams *S) *gorm.DB
+}
+
+// GormRepository is a generic gorm backed implementation of BaseRepository.
+// T is expected to implement model.Identifiable[K], as the models embedding model.IdentifiedModel do.
+// Each write is made in a transaction along with its audit log entries and versions.
+type GormRepository[T any, K comparable, S any] struct {
+	Db *gorm.DB
+	// IdColumn is the primary key column, "id" by default.
+	IdColumn string
+	filters  FilterApplier[S]
+	ctx      context.Context
+}
+
+// NewGormRepository creates a GormRepository. filters may be nil when the entity has no search filters.
+func NewGormRepository[T any, K comparable, S any](db *gorm.DB, filters FilterApplier[S]) *GormRepository[T, K, S] {
+	return &GormRepository[T, K, S]{
+		Db:       db,
+		IdColumn: "id",
+		filters:  filters,
+	}
+}
+
+func (e *GormRepository[T, K, S]) GetDB() *gorm.DB {
+	return e.Db
+}
+
+// WithContext returns a copy of the repository bound to ctx.
+func (e *GormRepository[T, K, S]) WithContext(ctx context.Context) BaseRepository[T, K, S] {
+	return e.bind(ctx)
+}
+
+func (e *GormRepository[T, K, S]) bind(ctx context.Context) *GormRepository[T, K, S] {
+	bound := *e
+	bound.ctx = ctx
+	return &bound
+}
+
+func (e *GormRepository[T, K, S]) Save(tx *gorm.DB, filterContext *S, item *T) (*T, error) {
+	return item, inTransaction(e.getDB(tx), func(db *gorm.DB) error {
+		if err := db.Omit(clause.Associations).Create(item).Error; err != nil {
+			return err
+		}
+		return e.recordWrite(db, model.AuditCreate, []K{e.idOf(item)}, nil)
+	})
+}
+
+func (e *GormRepository[T, K, S]) SaveMany(tx *gorm.DB, filterContext *S, items []*T) ([]*T, error) {
+	return items, inTransaction(e.getDB(tx), func(db *gorm.DB) error {
+		if err := db.Omit(clause.Associations).Create(items).Error; err != nil {
+			return err
+		}
+		itemIds := make([]K, 0, len(items))
+		for _, item := range items {
+			itemIds = append(itemIds, e.idOf(item))
+		}
+		return e.recordWrite(db, model.AuditCreate, itemIds, nil)
+	})
+}
+
+func (e *GormRepository[T, K, S]) Update(tx *gorm.DB, filterContext *S, item *T) (*T, error) {
+	return e.update(e.getDB(tx), filterContext, item, false)
+}
+
+func (e *GormRepository[T, K, S]) Replace(tx *gorm.DB, filterContext *S, item *T) (*T, error) {
+	return e.update(e.getDB(tx), filterContext, item, true)
+}
+
+func (e *GormRepository[T, K, S]) update(db *gorm.DB, filterContext *S, item *T, exact bool) (*T, error) {
+	var updated *T
+	err := inTransaction(db, func(db *gorm.DB) error {
+		itemIds := []K{e.idOf(item)}
+		before, err := e.snapshot(db, itemIds)
+		if err != nil {
+			return err
+		}
+		if err = e.updateItem(db, item, exact); err != nil {
+			return err
+		}
+		if err = e.recordWrite(db, model.AuditUpdate, itemIds, before); err != nil {
+			return err
+		}
+		updated, err = e.FindById(db, filterContext, e.idOf(item))
+		return err
+	})
+	return updated, err
+}
+
+func (e *GormRepository[T, K, S]) UpdateMany(tx *gorm.DB, filterContext *S, items []*T) ([]*T, error) {
+	var updated []*T
+	err := inTransaction(e.getDB(tx), func(db *gorm.DB) error {
+		itemIds := make([]K, 0, len(items))
+		for _, item := range items {
+			itemIds = append(itemIds, e.idOf(item))
+		}
+		before, err := e.snapshot(db, itemIds)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err = e.updateItem(db, item, false); err != nil {
+				return err
+			}
+		}
+		if err = e.recordWrite(db, model.AuditUpdate, itemIds, before); err != nil {
+			return err
+		}
+		updated, err = e.FindByIds(db, filterContext, itemIds)
+		return err
+	})
+	return updated, err
+}
+
+func (e *GormRepository[T, K, S]) Delete(tx *gorm.DB, searchParams *S, itemId K) error {
+	return inTransaction(withSearchActor(e.getDB(tx), searchParams), func(db *gorm.DB) error {
+		return e.softDelete(db, []K{itemId})
+	})
+}
+
+func (e *GormRepository[T, K, S]) DeleteByIds(tx *gorm.DB, searchParams *S, itemIds []K) error {
+	return inTransaction(withSearchActor(e.getDB(tx), searchParams), func(db *gorm.DB) error {
+		return e.softDelete(db, itemIds)
+	})
+}
+
+func (e *GormRepository[T, K, S]) Restore(tx *gorm.DB, searchParams *S, itemIds []K) error {
+	return inTransaction(e.getDB(tx), func(db *gorm.DB) error {
+		return e.restore(db, itemIds)
+	})
+}
+
+func (e *GormRepository[T, K, S]) restore(db *gorm.DB, itemIds []K) error {
+	before, err := e.snapshot(db, itemIds)
+	if err != nil {
+		return err
+	}
+	// date_updated moves forward so that the restored entity shows up in change feeds
+	values := map[string]interface{}{deletedAtColumn: nil, updatedAtColumn: time.Now()}
+	if e.hasColumn(db, deletedByColumn) {
+		values[deletedByColumn] = ""
+	}
+	if e.hasColumn(db, versionColumn) {
+		values[versionColumn] = gorm.Expr("? + 1", e.column(versionColumn))
+	}
+
+	result := db.Unscoped().Model(new(T)).
+		Where(e.idIn(itemIds)).
+		Where(clause.Neq{Column: e.column(deletedAtColumn), Value: nil}).
+		UpdateColumns(values)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return e.recordWrite(db, model.AuditRestore, itemIds, before)
+}
+
+func (e *GormRepository[T, K, S]) Purge(tx *gorm.DB, searchParams *S, itemIds []K) error {
+	return inTransaction(e.getDB(tx), func(db *gorm.DB) error {
+		return e.purge(db, itemIds)
+	})
+}
+
+func (e *GormRepository[T, K, S]) purge(db *gorm.DB, itemIds []K) error {
+	before, err := e.snapshot(db, itemIds)
+	if err != nil {
+		return err
+	}
+	// the purged entities of a changes feed are reported by their tombstone
+	if _, tracked := any(new(T)).(model.Tracked); tracked && before == nil {
+		if before, err = e.load(db, itemIds); err != nil {
+			return err
+		}
+	}
+	purged := db.Unscoped().Where(e.idIn(itemIds))
+	condition, conditional := e.precondition(db, itemIds)
+	if conditional {
+		purged = purged.Where(condition)
+	}
+	result := purged.Delete(new(T))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 && conditional {
+		return ErrPreconditionFailed
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	if err = e.recordPurge(db, before); err != nil {
+		return err
+	}
+	return e.recordWrite(db, model.AuditPurge, itemIds, before)
+}
+
+func (e *GormRepository[T, K, S]) FindById(tx *gorm.DB, searchParams *S, itemId K) (*T, error) {
+	db := e.getDB(tx)
+	// the version and the update time identify the state of the entity, they are read whatever
+	// the requested fields for the entity tag of the response to be computed
+	var stateColumns []string
+	for _, column := range []string{versionColumn, updatedAtColumn} {
+		if e.hasColumn(db, column) {
+			stateColumns = append(stateColumns, column)
+		}
+	}
+	db, err := e.reading(db, searchParams, stateColumns...)
+	if err != nil {
+		return nil, err
+	}
+	var entity T
+	result := db.Where(e.idEquals(itemId)).First(&entity)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &entity, nil
+}
+
+func (e *GormRepository[T, K, S]) FindByIds(tx *gorm.DB, searchParams *S, itemIds []K) ([]*T, error) {
+	db, err := e.reading(e.getDB(tx), searchParams)
+	if err != nil {
+		return nil, err
+	}
+	var entities []T
+	result := db.Where(e.idIn(itemIds)).Find(&entities)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return helper.ConvertSliceToReference(entities), nil
+}
+
+func (e *GormRepository[T, K, S]) FindAll(tx *gorm.DB, searchParams *S, pageSize int, page int) ([]*T, error) {
+	db := e.getDB(tx)
+	var entities []*T
+	pageRequest := PageRequest{PageSize: pageSize, PageNumber: page}
+	result := db.Limit(pageRequest.Limit()).Offset(pageRequest.Offset()).Find(&entities)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return entities, nil
+}
+
+func (e *GormRepository[T, K, S]) Search(tx *gorm.DB, searchParams *S) ([]*T, error) {
+	db, err := e.reading(e.applyFilters(e.getDB(tx), searchParams), searchParams)
+	if err != nil {
+		return nil, err
+	}
+	pageRequest := pageRequestOf(searchParams)
+	var entities []*T
+
+	sortFields, err := SortRegistryOf[T](db, searchParams).Parse(pageRequest.OrderBy)
+	if err != nil {
+		return nil, err
+	}
+	for _, sortField := range sortFields {
+		db = db.Order(clause.OrderByColumn{Column: filterColumn(sortField.Column), Desc: sortField.Desc})
+	}
+	if query := textQueryOf(searchParams); query != "" && len(sortFields) == 0 && isTextSearchable[T](db) {
+		db = rankByRelevance(db, query)
+	}
+
+	result := db.Limit(pageRequest.Limit()).Offset(pageRequest.Offset()).Find(&entities)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return entities, nil
+}
+
+func (e *GormRepository[T, K, S]) SearchByCursor(tx *gorm.DB, searchParams *S) ([]*T, string, error) {
+	db := e.applyFilters(e.getDB(tx), searchParams)
+	pageRequest := pageRequestOf(searchParams)
+	pageSize := pageRequest.Limit()
+
+	sortFields, err := SortRegistryOf[T](db, searchParams).Parse(pageRequest.OrderBy)
+	if err != nil {
+		return nil, "", err
+	}
+	// the cursor is encoded from the sort columns, which are read whatever the requested fields
+	sortColumns := make([]string, 0, len(sortFields))
+	for _, sortField := range sortFields {
+		sortColumns = append(sortColumns, sortField.Column)
+	}
+	db, err = e.reading(db, searchParams, sortColumns...)
+	if err != nil {
+		return nil, "", err
+	}
+	keys, err := newKeyset(db, new(T), sortFields, e.IdColumn)
+	if err != nil {
+		return nil, "", err
+	}
+	db = keys.order(db)
+	if pageRequest.Cursor != "" {
+		db, err = keys.after(db, pageRequest.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	// fetch one extra entity to know whether there is a next page
+	var entities []*T
+	result := db.Limit(pageSize + 1).Find(&entities)
+	if result.Error != nil {
+		return nil, "", result.Error
+	}
+	if len(entities) <= pageSize {
+		return entities, "", nil
+	}
+
+	entities = entities[:pageSize]
+	nextCursor, err := keys.encode(entities[pageSize-1])
+	if err != nil {
+		return nil, "", err
+	}
+	return entities, nextCursor, nil
+}
+
+func (e *GormRepository[T, K, S]) Changes(tx *gorm.DB, searchParams *S, since string) (*ChangeSet[T], error) {
+	if _, ok := any(new(T)).(model.Tracked); !ok {
+		return nil, apperror.NotFound("changes_unsupported", "entity does not support change tracking")
+	}
+
+	db, err := preload(e.applyFilters(e.getDB(tx).Unscoped(), searchParams), searchParams)
+	if err != nil {
+		return nil, err
+	}
+	pageSize := pageRequestOf(searchParams).Limit()
+
+	var changedAt time.Time
+	var afterId *K
+	if since != "" {
+		if changedAt, afterId, err = decodeSyncToken[K](since); err != nil {
+			return nil, err
+		}
+		db = afterChange(db, changedAt, afterId, e.column(e.IdColumn))
+	}
+
+	// fetch one extra change to know whether there are more changes
+	var entities []*T
+	result := db.
+		Order(clause.OrderBy{Expression: clause.Expr{SQL: "?, ?", Vars: []interface{}{changedAtExpression(), e.column(e.IdColumn)}}}).
+		Limit(pageSize + 1).
+		Find(&entities)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	purged, err := e.purgedAfter(e.getDB(tx), changedAt, afterId, pageSize+1)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := mergeChanges(entities, purged, e.idOf)
+	changes := &ChangeSet[T]{SyncToken: since, HasMore: len(merged) > pageSize}
+	if changes.HasMore {
+		merged = merged[:pageSize]
+	}
+	for _, entry := range merged {
+		if entry.purged != nil {
+			changes.Deleted = append(changes.Deleted, *entry.purged.Tombstone())
+		} else if tombstone := any(entry.entity).(model.Tracked).Tombstone(); tombstone != nil {
+			changes.Deleted = append(changes.Deleted, *tombstone)
+		} else {
+			changes.Upserted = append(changes.Upserted, entry.entity)
+		}
+	}
+
+	if len(merged) > 0 {
+		last := merged[len(merged)-1]
+		lastId, err := parseEntityId[K](last.id)
+		if err != nil {
+			return nil, err
+		}
+		syncToken, err := encodeSyncToken(last.changedAt, lastId)
+		if err != nil {
+			return nil, err
+		}
+		changes.SyncToken = syncToken
+	}
+	return changes, nil
+}
+
+func (e *GormRepository[T, K, S]) Count(tx *gorm.DB, searchParams *S) (int64, error) {
+	db := e.applyFilters(e.getDB(tx).Model(new(T)), searchParams)
+	var count int64
+	result := db.Count(&count)
+	return count, result.Error
+}
+
+func (e *GormRepository[T, K, S]) Deleted(tx *gorm.DB, searchParams *S) ([]string, error) {
+	db := e.getDB(tx).Unscoped().Model(new(T)).Where(clause.Neq{Column: e.column(deletedAtColumn), Value: nil})
+	db = e.applyFilters(db, searchParams)
+	pageRequest := pageRequestOf(searchParams)
+	var entities []string
+
+	result := db.Limit(pageRequest.Limit()).Pluck(e.IdColumn, &entities)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return entities, nil
+}
+
+// updateItem writes the non zero fields of item, all of its columns when exact is true, but for its
+// associations and its creation, deletion and unexposed columns. The version is checked and incremented.
+func (e *GormRepository[T, K, S]) updateItem(db *gorm.DB, item *T, exact bool) error {
+	updates := func(db *gorm.DB, omitted ...string) *gorm.DB {
+		omitted = append(omitted, clause.Associations, createdAtColumn, createdByColumn, deletedAtColumn, deletedByColumn)
+		omitted = append(omitted, unexposedColumns[T](db)...)
+		if !exact {
+			return db.Omit(omitted...).Updates(item)
+		}
+		return db.Select("*").Omit(omitted...).Updates(item)
+	}
+
+	stored := db.Model(item)
+	condition, conditional := e.precondition(db, []K{e.idOf(item)})
+	if conditional {
+		stored = stored.Where(condition)
+	}
+
+	versioned, ok := any(item).(model.Versioned)
+	if !ok {
+		return checkPrecondition(updates(stored), conditional)
+	}
+
+	expectedVersion := versioned.GetVersion()
+	if expectedVersion == 0 {
+		if err := checkPrecondition(updates(stored, versionColumn), conditional); err != nil {
+			return err
+		}
+		return db.Model(item).Omit(clause.Associations).UpdateColumn(versionColumn, gorm.Expr("? + 1", e.column(versionColumn))).Error
+	}
+
+	versioned.SetVersion(expectedVersion + 1)
+	result := updates(stored.Where(clause.Eq{Column: e.column(versionColumn), Value: expectedVersion}))
+	if result.Error != nil || result.RowsAffected == 0 {
+		versioned.SetVersion(expectedVersion)
+	}
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 && conditional {
+		return ErrPreconditionFailed
+	}
+	if result.RowsAffected == 0 {
+		return e.versionConflict(db, e.idOf(item))
+	}
+	return nil
+}
+
+// unexposedColumns returns the columns of the fields of T left out of its JSON, e.g. the foreign keys
+// tagged `json:"-"`. A replacement decoded from a request body has them zeroed, so they are kept.
+func unexposedColumns[T any](db *gorm.DB) []string {
+	modelSchema, err := schemaOf(db, new(T))
+	if err != nil {
+		return nil
+	}
+	var columns []string
+	for _, field := range modelSchema.Fields {
+		name, _, _ := strings.Cut(field.StructField.Tag.Get("json"), ",")
+		if field.DBName != "" && name == "-" {
+			columns = append(columns, field.DBName)
+		}
+	}
+	return columns
+}
+
+// versionConflict reports why a versioned update did not affect any row.
+func (e *GormRepository[T, K, S]) versionConflict(db *gorm.DB, itemId K) error {
+	var versions []int64
+	result := db.Session(&gorm.Session{NewDB: true}).Model(new(T)).Where(e.idEquals(itemId)).Pluck(versionColumn, &versions)
+	if result.Error != nil {
+		return result.Error
+	}
+	if len(versions) == 0 {
+		return ErrNotFound
+	}
+	return &VersionConflictError{CurrentVersion: versions[0]}
+}
+
+// withSearchActor sets the actor of the deprecated PageRequest.SetActor on a context without one.
+func withSearchActor[S any](db *gorm.DB, searchParams *S) *gorm.DB {
+	actor := pageRequestOf(searchParams).Actor()
+	if actor == "" || ActorFromContext(db.Statement.Context) != "" {
+		return db
+	}
+	return db.WithContext(WithActor(db.Statement.Context, actor))
+}
+
+// softDelete fills DeletedBy with the actor of the context and increments the version, then soft
+// deletes the entities.
+func (e *GormRepository[T, K, S]) softDelete(db *gorm.DB, itemIds []K) error {
+	before, err := e.snapshot(db, itemIds)
+	if err != nil {
+		return err
+	}
+	values := map[string]interface{}{}
+	if actor := ActorFromContext(db.Statement.Context); actor != "" && e.hasColumn(db, deletedByColumn) {
+		values[deletedByColumn] = actor
+	}
+	if e.hasColumn(db, versionColumn) {
+		values[versionColumn] = gorm.Expr("? + 1", e.column(versionColumn))
+	}
+	// the precondition is checked by the first statement, the version being incremented by it
+	condition, conditional := e.precondition(db, itemIds)
+	deleted := db.Where(e.idIn(itemIds))
+	if conditional {
+		deleted = deleted.Where(condition)
+	}
+	if len(values) > 0 {
+		if err := checkPrecondition(deleted.Model(new(T)).UpdateColumns(values), conditional); err != nil {
+			return err
+		}
+		deleted = db.Where(e.idIn(itemIds))
+	}
+	if err := checkPrecondition(deleted.Delete(new(T)), conditional && len(values) == 0); err != nil {
+		return err
+	}
+	return e.recordWrite(db, model.AuditDelete, itemIds, before)
+}
+
+// hasColumn tells whether the table of T has the given column.
+func (e *GormRepository[T, K, S]) hasColumn(db *gorm.DB, column string) bool {
+	modelSchema, err := schemaOf(db, new(T))
+	if err != nil {
+		return false
+	}
+	_, ok := modelSchema.FieldsByDBName[column]
+	return ok
+}
+
+var defaultSchemas sync.Map
+
+// schemaOf returns the schema of model parsed with the naming strategy of db, and with the default
+// one when db is nil.
+func schemaOf(db *gorm.DB, model any) (*schema.Schema, error) {
+	if db == nil {
+		return schema.Parse(model, &defaultSchemas, schema.NamingStrategy{})
+	}
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	return stmt.Schema, nil
+}
+
+// getDB returns tx when set, then the transaction carried by the bound context, and the
+// repository connection otherwise, itself carrying the bound context.
+func (e *GormRepository[T, K, S]) getDB(tx *gorm.DB) *gorm.DB {
+	if tx != nil {
+		return tx
+	}
+	if e.ctx == nil {
+		return e.Db
+	}
+	if tx = TxFromContext(e.ctx); tx != nil {
+		return tx
+	}
+	return e.Db.WithContext(e.ctx)
+}
+
+// reading restricts db to the fields requested by searchParams, along with the given columns, and
+// loads the relations it includes.
+func (e *GormRepository[T, K, S]) reading(db *gorm.DB, searchParams *S, columns ...string) (*gorm.DB, error) {
+	db, err := e.selectFields(db, searchParams, columns...)
+	if err != nil {
+		return nil, err
+	}
+	return preload(db, searchParams)
+}
+
+func (e *GormRepository[T, K, S]) applyFilters(db *gorm.DB, searchParams *S) *gorm.DB {
+	if searchParams == nil {
+		return db
+	}
+	db = ApplySearchFilters(db, searchParams)
+	if query := textQueryOf(searchParams); query != "" && isTextSearchable[T](db) {
+		db = applyTextSearch(db, query)
+	}
+	if e.filters != nil {
+		db = e.filters.ApplyFilters(db, searchParams)
+	}
+	return db
+}
+
+// column qualifies name with the table of the current statement, so that it stays unambiguous in joins.
+func (e *GormRepository[T, K, S]) column(name string) clause.Column {
+	return clause.Column{Table: clause.CurrentTable, Name: name}
+}
+
+func (e *GormRepository[T, K, S]) idEquals(id K) clause.Expression {
+	return clause.Eq{Column: e.column(e.IdColumn), Value: id}
+}
+
+func (e *GormRepository[T, K, S]) idIn(ids []K) clause.Expression {
+	values := make([]interface{}, len(ids))
+	for i, id := range ids {
+		values[i] = id
+	}
+	return clause.IN{Column: e.column(e.IdColumn), Values: values}
+}
+
+func (e *GormRepository[T, K, S]) idOf(item *T) K {
+	if identifiable, ok := any(item).(model.Identifiable[K]); ok {
+		return identifiable.GetId()
+	}
+	var zero K
+	return zero
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/roksky/bootstrap-api/apperror"
+	"github.com/roksky/bootstrap-api/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrHistoryDisabled is returned when the versions of an entity that keeps no history are requested.
+var ErrHistoryDisabled = apperror.NotFound("history_disabled", "entity does not keep a version history")
+
+// HistoryRepository gives access to the versions of entities implementing model.Historized.
+// GormRepository records a version after every write of such entities.
+type HistoryRepository[T any, K comparable] interface {
+	// Versions returns the versions of an entity, oldest first. When at is set, only the version
+	// in effect at that time is returned.
+	// tx is an optional transaction. If nil, the default DB is used.
+	Versions(tx *gorm.DB, itemId K, at *time.Time) ([]*model.EntityVersion, error)
+
+	// Version returns the given version of an entity, ErrNotFound when there is none.
+	// tx is an optional transaction. If nil, the default DB is used.
+	Version(tx *gorm.DB, itemId K, version int64) (*model.EntityVersion, error)
+}
+
+func (e *GormRepository[T, K, S]) Versions(tx *gorm.DB, itemId K, at *time.Time) ([]*model.EntityVersion, error) {
+	db, err := e.historyDB(tx, itemId)
+	if err != nil {
+		return nil, err
+	}
+	var versions []*model.EntityVersion
+	if at != nil {
+		db = db.Where(clause.Lte{Column: clause.Column{Name: "date_created"}, Value: *at}).
+			Order(clause.OrderByColumn{Column: clause.Column{Name: "version"}, Desc: true}).Limit(1)
+	} else {
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: "version"}})
+	}
+	if err = db.Find(&versions).Error; err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (e *GormRepository[T, K, S]) Version(tx *gorm.DB, itemId K, version int64) (*model.EntityVersion, error) {
+	db, err := e.historyDB(tx, itemId)
+	if err != nil {
+		return nil, err
+	}
+	var entityVersion model.EntityVersion
+	result := db.Where(clause.Eq{Column: clause.Column{Name: "version"}, Value: version}).First(&entityVersion)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &entityVersion, nil
+}
+
+// historyDB selects the history rows of an entity, after checking that the entity, deleted or not,
+// is visible to the caller.
+func (e *GormRepository[T, K, S]) historyDB(tx *gorm.DB, itemId K) (*gorm.DB, error) {
+	historyTable, historized := historyTableOf[T]()
+	if !historized {
+		return nil, ErrHistoryDisabled
+	}
+
+	db := e.getDB(tx)
+	var count int64
+	if err := db.Session(&gorm.Session{NewDB: true}).Unscoped().Model(new(T)).Where(e.idEquals(itemId)).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrNotFound
+	}
+	return db.Session(&gorm.Session{NewDB: true}).Table(historyTable).
+		Where(clause.Eq{Column: clause.Column{Name: "entity_id"}, Value: fmt.Sprint(itemId)}), nil
+}
+
+// recordVersions stores the current state of the written entities in the history table.
+func (e *GormRepository[T, K, S]) recordVersions(db *gorm.DB, historyTable string, action model.AuditAction, ids []K, current map[K]*T) error {
+	actor := ActorFromContext(db.Statement.Context)
+	versions := make([]*model.EntityVersion, 0, len(ids))
+	recorded := make(map[K]bool, len(ids))
+	for _, id := range ids {
+		entity, ok := current[id]
+		if !ok || recorded[id] {
+			continue
+		}
+		recorded[id] = true
+		snapshot, err := json.Marshal(entity)
+		if err != nil {
+			return err
+		}
+		versions = append(versions, &model.EntityVersion{
+			EntityId: fmt.Sprint(id),
+			Version:  any(entity).(model.Versioned).GetVersion(),
+			Action:   action,
+			Snapshot: snapshot,
+			Actor:    actor,
+		})
+	}
+	if len(versions) == 0 {
+		return nil
+	}
+	return db.Session(&gorm.Session{NewDB: true}).Table(historyTable).Create(&versions).Error
+}
+
+// historyTableOf returns the history table of T, when T is a versioned model keeping a history.
+func historyTableOf[T any]() (string, bool) {
+	if _, versioned := any(new(T)).(model.Versioned); !versioned {
+		return "", false
+	}
+	historized, ok := any(new(T)).(model.Historized)
+	if !ok {
+		return "", false
+	}
+	return historized.HistoryTable(), true
+}
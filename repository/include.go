@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/roksky/bootstrap-api/apperror"
+	"gorm.io/gorm"
+)
+
+// InvalidIncludeError is returned when an include parameter names a relation that is not allowed.
+type InvalidIncludeError struct {
+	Relation string
+	Allowed  []string
+}
+
+func (e *InvalidIncludeError) Error() string {
+	if len(e.Allowed) == 0 {
+		return fmt.Sprintf("cannot include %q, the entity has no includable relations", e.Relation)
+	}
+	return fmt.Sprintf("cannot include %q, allowed relations are %s", e.Relation, strings.Join(e.Allowed, ", "))
+}
+
+func (e *InvalidIncludeError) AppError() *apperror.Error {
+	return apperror.Validation("invalid_include", e.Error()).With("allowedRelations", e.Allowed)
+}
+
+// IncludeRegistry maps the relation names clients may include, in their JSON form, to the gorm
+// preload paths of the model, e.g. "systemUser" to "SystemUser". Nested relations are declared
+// with their full path, e.g. "organization.owner" to "Organization.Owner".
+type IncludeRegistry struct {
+	relations map[string]string
+}
+
+func NewIncludeRegistry(relations map[string]string) *IncludeRegistry {
+	return &IncludeRegistry{relations: relations}
+}
+
+// AllowedRelations returns the includable relation names in alphabetical order.
+func (r *IncludeRegistry) AllowedRelations() []string {
+	allowed := make([]string, 0, len(r.relations))
+	for relation := range r.relations {
+		allowed = append(allowed, relation)
+	}
+	sort.Strings(allowed)
+	return allowed
+}
+
+// Parse validates an include parameter of the form "relation,relation.nested" and resolves the
+// preload paths of its relations.
+func (r *IncludeRegistry) Parse(include string) ([]string, error) {
+	var paths []string
+	for _, relation := range strings.Split(include, ",") {
+		relation = strings.TrimSpace(relation)
+		if relation == "" {
+			continue
+		}
+		path, ok := r.relations[relation]
+		if !ok {
+			return nil, &InvalidIncludeError{Relation: relation, Allowed: r.AllowedRelations()}
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// Includable is implemented by search structs whose entity has relations clients may include.
+type Includable interface {
+	IncludeRegistry() *IncludeRegistry
+}
+
+// IncludeRegistryOf returns the include registry declared by the search struct, or an empty one
+// when the search struct is not Includable: relations are only loaded when they are whitelisted.
+func IncludeRegistryOf[S any](searchParams *S) *IncludeRegistry {
+	if searchParams == nil {
+		searchParams = new(S)
+	}
+	if includable, ok := any(searchParams).(Includable); ok {
+		return includable.IncludeRegistry()
+	}
+	return NewIncludeRegistry(nil)
+}
+
+// preload adds the relations named by the include parameter of searchParams to db. gorm loads
+// every relation, nested ones included, with one query per relation for all the entities found.
+func preload[S any](db *gorm.DB, searchParams *S) (*gorm.DB, error) {
+	include := pageRequestOf(searchParams).Include
+	if include == "" {
+		return db, nil
+	}
+	paths, err := IncludeRegistryOf(searchParams).Parse(include)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		db = db.Preload(path)
+	}
+	return db, nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+// DefaultPageSize is the page size of the searches that do not set one.
+const DefaultPageSize = 100
+
+// MaxPageSize bounds the page size of the searches.
+var MaxPageSize = 1000
+
+// PageRequest holds the paging parameters shared by the search structs, which embed it.
+// OrderBy takes "field:direction,field", e.g. "dateCreated:desc,name", Include the relations to
+// load, e.g. "systemUser", and Fields the JSON fields to read, e.g. "id,name".
+type PageRequest struct {
+	PageSize   int    `query:"pageSize" default:"100"`
+	PageNumber int    `query:"pageNumber" default:"0"`
+	OrderBy    string `query:"orderBy"`
+	Cursor     string `query:"cursor"`
+	Include    string `query:"include"`
+	Fields     string `query:"fields"`
+	actor      string
+}
+
+// SetActor records the user performing the request, e.g. to fill DeletedBy.
+//
+// Deprecated: use WithActor.
+func (p *PageRequest) SetActor(actor string) {
+	p.actor = actor
+}
+
+// Actor returns the user set by SetActor.
+//
+// Deprecated: use ActorFromContext.
+func (p *PageRequest) Actor() string {
+	return p.actor
+}
+
+// Limit returns the number of entities of a page, PageSize bounded by MaxPageSize.
+func (p *PageRequest) Limit() int {
+	return BoundPageSize(p.PageSize)
+}
+
+// Offset returns the number of entities before the page.
+func (p *PageRequest) Offset() int {
+	if p.PageNumber <= 0 {
+		return 0
+	}
+	return p.PageNumber * p.Limit()
+}
+
+// BoundPageSize returns pageSize bounded by MaxPageSize, and DefaultPageSize when it is not positive.
+func BoundPageSize(pageSize int) int {
+	if pageSize <= 0 {
+		return DefaultPageSize
+	}
+	if pageSize > MaxPageSize {
+		return MaxPageSize
+	}
+	return pageSize
+}
+
+// GetPageRequest returns the paging parameters of a search struct embedding PageRequest.
+func (p *PageRequest) GetPageRequest() *PageRequest {
+	return p
+}
+
+// Pageable is implemented by every search struct that embeds PageRequest.
+type Pageable interface {
+	GetPageRequest() *PageRequest
+}
+
+// pageRequestOf returns the paging parameters of searchParams, or an empty PageRequest
+// when the search struct does not embed one.
+func pageRequestOf[S any](searchParams *S) *PageRequest {
+	if searchParams == nil {
+		return &PageRequest{}
+	}
+	if pageable, ok := any(searchParams).(Pageable); ok {
+		return pageable.GetPageRequest()
+	}
+	return &PageRequest{}
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/roksky/bootstrap-api/apperror"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrPreconditionFailed is returned when a write is made under a Precondition that the stored
+// entity no longer meets, as it was modified since the client read it.
+var ErrPreconditionFailed = apperror.New(apperror.KindPrecondition, "precondition_failed", "the entity was modified since it was read")
+
+// Precondition is the state a client expects an entity to be in for a write to be made, e.g. the
+// one of the entity tag of its If-Match header. The state is checked by the write statement itself,
+// so that a concurrent modification cannot slip between the check and the write.
+type Precondition struct {
+	// Id is the id of the entity the precondition applies to.
+	Id any
+	// Version is the expected version of a versioned entity, see model.Versioned.
+	Version int64
+	// UpdatedAt is the expected update time of the entities that are not versioned.
+	UpdatedAt time.Time
+}
+
+type preconditionKey struct{}
+
+// WithPrecondition returns a copy of ctx under which the single-entity writes on the entity of
+// precondition, its update, deletion and purge, fail with ErrPreconditionFailed unless the stored
+// entity meets it.
+func WithPrecondition(ctx context.Context, precondition Precondition) context.Context {
+	return context.WithValue(ctx, preconditionKey{}, precondition)
+}
+
+// PreconditionFromContext returns the precondition set with WithPrecondition, if any.
+func PreconditionFromContext(ctx context.Context) (Precondition, bool) {
+	if ctx == nil {
+		return Precondition{}, false
+	}
+	precondition, ok := ctx.Value(preconditionKey{}).(Precondition)
+	return precondition, ok
+}
+
+// precondition returns the condition on the stored state of the entity of a write on itemIds, from
+// the precondition set on the context of db, and false when the write is not under a precondition.
+func (e *GormRepository[T, K, S]) precondition(db *gorm.DB, itemIds []K) (clause.Expression, bool) {
+	precondition, ok := PreconditionFromContext(db.Statement.Context)
+	if !ok || len(itemIds) != 1 || precondition.Id != any(itemIds[0]) {
+		return nil, false
+	}
+	if precondition.Version != 0 && e.hasColumn(db, versionColumn) {
+		return clause.Eq{Column: e.column(versionColumn), Value: precondition.Version}, true
+	}
+	if !precondition.UpdatedAt.IsZero() && e.hasColumn(db, updatedAtColumn) {
+		return clause.Eq{Column: e.column(updatedAtColumn), Value: precondition.UpdatedAt}, true
+	}
+	return nil, false
+}
+
+// checkPrecondition returns the error of result, and ErrPreconditionFailed when the statement of a
+// conditional write did not affect any row.
+func checkPrecondition(result *gorm.DB, conditional bool) error {
+	if result.Error != nil {
+		return result.Error
+	}
+	if conditional && result.RowsAffected == 0 {
+		return ErrPreconditionFailed
+	}
+	return nil
+}
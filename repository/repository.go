@@ -1,31 +1,38 @@
 package repository
 
-import "gorm.io/gorm"
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
 
 // BaseRepository defines a generic repository interface for CRUD operations.
 // T represents the type of the entity.
 // K represents the type of the entity's ID.
 // S represents the type of the search parameters.
 // Many methods accept an optional *gorm.DB transaction (tx). If tx is nil, the default DB connection is used.
+// The other capabilities of a repository are optional interfaces, such as CursorRepository.
 type BaseRepository[T any, K comparable, S any] interface {
 	// GetDB returns the gorm.DB instance.
 	GetDB() *gorm.DB
 
-	// Save saves a single entity.
+	// Save saves a single entity, without its associations which are only referenced by their id.
 	// filterContext provides additional context for the operation.
 	// item is the entity to be saved.
 	// tx is an optional transaction. If nil, the default DB is used.
 	Save(tx *gorm.DB, filterContext *S, item *T) (*T, error)
 
-	// SaveMany saves multiple entities.
+	// SaveMany saves multiple entities, without their associations.
 	// filterContext provides additional context for the operation.
 	// item is the list of entities to be saved.
 	// tx is an optional transaction. If nil, the default DB is used.
 	SaveMany(tx *gorm.DB, filterContext *S, item []*T) ([]*T, error)
 
-	// Update updates a single entity.
+	// Update updates a single entity, without its associations and the foreign keys left out of its JSON.
 	// filterContext provides additional context for the operation.
 	// item is the entity to be updated.
+	// Versioned entities are only updated when their version matches the stored one, otherwise a
+	// *VersionConflictError carrying the current version is returned.
 	// tx is an optional transaction. If nil, the default DB is used.
 	Update(tx *gorm.DB, filterContext *S, item *T) (*T, error)
 
@@ -35,14 +42,14 @@ type BaseRepository[T any, K comparable, S any] interface {
 	// tx is an optional transaction. If nil, the default DB is used.
 	UpdateMany(tx *gorm.DB, filterContext *S, item []*T) ([]*T, error)
 
-	// Delete deletes a single entity by its ID.
-	// searchParams provides additional context for the operation.
+	// Delete soft deletes a single entity by its ID, incrementing the version of versioned entities.
+	// searchParams provides additional context for the operation. The actor of the context, see WithActor, fills DeletedBy.
 	// itemId is the ID of the entity to be deleted.
 	// tx is an optional transaction. If nil, the default DB is used.
 	Delete(tx *gorm.DB, searchParams *S, itemId K) error
 
-	// DeleteByIds deletes multiple entities by their IDs.
-	// searchParams provides additional context for the operation.
+	// DeleteByIds soft deletes multiple entities by their IDs, incrementing the version of versioned entities.
+	// searchParams provides additional context for the operation. The actor of the context, see WithActor, fills DeletedBy.
 	// itemIds is the list of IDs of the entities to be deleted.
 	// tx is an optional transaction. If nil, the default DB is used.
 	DeleteByIds(tx *gorm.DB, searchParams *S, itemIds []K) error
@@ -81,3 +88,77 @@ type BaseRepository[T any, K comparable, S any] interface {
 	// tx is an optional transaction. If nil, the default DB is used.
 	Deleted(tx *gorm.DB, searchParams *S) ([]string, error)
 }
+
+// ContextRepository is implemented by the repositories that can be bound to a context.
+type ContextRepository[T any, K comparable, S any] interface {
+	// WithContext returns a copy of the repository bound to ctx.
+	// When tx is nil, its methods run in the transaction carried by ctx, see WithTransaction,
+	// or on the default DB with ctx, so that cancelling ctx cancels their queries.
+	WithContext(ctx context.Context) BaseRepository[T, K, S]
+}
+
+// ReplaceRepository is implemented by the repositories that can overwrite an entity.
+type ReplaceRepository[T any, K comparable, S any] interface {
+	// Replace updates every column of a single entity, zero values included, but the ones recording
+	// its creation and deletion, such as DateCreated and DeletedBy, and the ones of the fields left
+	// out of its JSON, such as the foreign keys tagged `json:"-"`.
+	// filterContext provides additional context for the operation.
+	// item is the entity to be replaced.
+	// Versioned entities are only updated when their version matches the stored one, as with Update.
+	// tx is an optional transaction. If nil, the default DB is used.
+	Replace(tx *gorm.DB, filterContext *S, item *T) (*T, error)
+}
+
+// RestoreRepository is implemented by the repositories that can restore and purge deleted entities.
+type RestoreRepository[T any, K comparable, S any] interface {
+	// Restore undoes the soft deletion of entities, clearing DateDeleted and DeletedBy and incrementing
+	// the version of versioned entities.
+	// searchParams provides additional context for the operation.
+	// itemIds is the list of IDs of the deleted entities to be restored.
+	// tx is an optional transaction. If nil, the default DB is used.
+	Restore(tx *gorm.DB, searchParams *S, itemIds []K) error
+
+	// Purge permanently removes entities, whether they are soft deleted or not. It returns
+	// ErrNotFound when none of them exists. The tombstones of tracked entities are kept for Changes.
+	// searchParams provides additional context for the operation.
+	// itemIds is the list of IDs of the entities to be purged.
+	// tx is an optional transaction. If nil, the default DB is used.
+	Purge(tx *gorm.DB, searchParams *S, itemIds []K) error
+}
+
+// CursorRepository is implemented by the repositories supporting keyset pagination.
+type CursorRepository[T any, K comparable, S any] interface {
+	// SearchByCursor searches for entities using keyset pagination.
+	// searchParams provides the criteria for the search, its cursor is the one returned with the previous page.
+	// Returns the entities of the page and the cursor of the next one, empty when there are no more entities.
+	// tx is an optional transaction. If nil, the default DB is used.
+	SearchByCursor(tx *gorm.DB, searchParams *S) ([]*T, string, error)
+}
+
+// ChangesRepository is implemented by the repositories feeding the changes of tracked entities.
+type ChangesRepository[T any, K comparable, S any] interface {
+	// Changes returns the entities created, updated, deleted or purged after the since sync token.
+	// The tombstones of purged entities are not filtered by searchParams.
+	// searchParams provides the criteria for the search, its page size bounds the number of changes.
+	// since is a sync token returned with a previous change set, an RFC 3339 timestamp, or empty for every change.
+	// tx is an optional transaction. If nil, the default DB is used.
+	Changes(tx *gorm.DB, searchParams *S, since string) (*ChangeSet[T], error)
+}
+
+// AggregateRepository is implemented by the repositories computing statistics over entities.
+type AggregateRepository[T any, K comparable, S any] interface {
+	// Aggregate computes statistics over the entities matching the search parameters, e.g. their
+	// count per value of a field, see AggregateRequest.
+	// searchParams provides the criteria for the search, its page size bounds the number of groups.
+	// request lists the fields to group on and the metrics to compute for each group.
+	// tx is an optional transaction. If nil, the default DB is used.
+	Aggregate(tx *gorm.DB, searchParams *S, request *AggregateRequest) ([]AggregateRow, error)
+}
+
+// Bind returns repo bound to ctx when it is a ContextRepository, and repo itself otherwise.
+func Bind[T any, K comparable, S any](repo BaseRepository[T, K, S], ctx context.Context) BaseRepository[T, K, S] {
+	if contextual, ok := repo.(ContextRepository[T, K, S]); ok && ctx != nil {
+		return contextual.WithContext(ctx)
+	}
+	return repo
+}
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Filter operators supported by the `filter` struct tag.
+const (
+	FilterEq      = "eq"
+	FilterNe      = "ne"
+	FilterIn      = "in"
+	FilterLike    = "like"
+	FilterILike   = "ilike"
+	FilterPrefix  = "prefix"
+	FilterGt      = "gt"
+	FilterGte     = "gte"
+	FilterLt      = "lt"
+	FilterLte     = "lte"
+	FilterBetween = "between"
+	FilterIsNull  = "isnull"
+)
+
+// ApplySearchFilters adds a where clause for every field of searchParams tagged with
+// `filter:"column=name,op=ilike"`, the column defaulting to the snake cased field name and the
+// operator to eq. Fields holding their zero value are skipped.
+func ApplySearchFilters(db *gorm.DB, searchParams any) *gorm.DB {
+	value := reflect.ValueOf(searchParams)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return db
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return db
+	}
+	return applyStructFilters(db, value)
+}
+
+func applyStructFilters(db *gorm.DB, value reflect.Value) *gorm.DB {
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			db = applyStructFilters(db, value.Field(i))
+			continue
+		}
+
+		tag, ok := field.Tag.Lookup("filter")
+		if !ok || tag == "-" {
+			continue
+		}
+
+		fieldValue := value.Field(i)
+		if fieldValue.IsZero() || (fieldValue.Kind() == reflect.Slice && fieldValue.Len() == 0) {
+			continue
+		}
+
+		column, operator := parseFilterTag(db, field.Name, tag)
+		expression, err := filterExpression(column, operator, fieldValue)
+		if err != nil {
+			_ = db.AddError(fmt.Errorf("filter on %s: %w", field.Name, err))
+			continue
+		}
+		db = db.Where(expression)
+	}
+	return db
+}
+
+func parseFilterTag(db *gorm.DB, fieldName string, tag string) (clause.Column, string) {
+	columnName := ""
+	operator := FilterEq
+	for _, option := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(option), "=")
+		switch key {
+		case "column":
+			columnName = value
+		case "op":
+			operator = strings.ToLower(value)
+		}
+	}
+	if columnName == "" {
+		columnName = db.NamingStrategy.ColumnName("", fieldName)
+	}
+	return filterColumn(columnName), operator
+}
+
+// filterColumn qualifies unqualified columns with the current table so that filters stay
+// unambiguous when the query joins other tables.
+func filterColumn(name string) clause.Column {
+	if table, column, ok := strings.Cut(name, "."); ok {
+		return clause.Column{Table: table, Name: column}
+	}
+	return clause.Column{Table: clause.CurrentTable, Name: name}
+}
+
+func filterExpression(column clause.Column, operator string, fieldValue reflect.Value) (clause.Expression, error) {
+	for fieldValue.Kind() == reflect.Pointer {
+		fieldValue = fieldValue.Elem()
+	}
+	value := fieldValue.Interface()
+
+	switch operator {
+	case FilterEq:
+		return clause.Eq{Column: column, Value: value}, nil
+	case FilterNe:
+		return clause.Neq{Column: column, Value: value}, nil
+	case FilterGt:
+		return clause.Gt{Column: column, Value: value}, nil
+	case FilterGte:
+		return clause.Gte{Column: column, Value: value}, nil
+	case FilterLt:
+		return clause.Lt{Column: column, Value: value}, nil
+	case FilterLte:
+		return clause.Lte{Column: column, Value: value}, nil
+	case FilterLike:
+		return likeExpression(column, "LIKE", "%"+escapeLike(value)+"%"), nil
+	case FilterILike:
+		return likeExpression(column, "ILIKE", "%"+escapeLike(value)+"%"), nil
+	case FilterPrefix:
+		return likeExpression(column, "LIKE", escapeLike(value)+"%"), nil
+	case FilterIn:
+		values, err := sliceValues(fieldValue)
+		if err != nil {
+			return nil, err
+		}
+		return clause.IN{Column: column, Values: values}, nil
+	case FilterBetween:
+		values, err := sliceValues(fieldValue)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) != 2 {
+			return nil, fmt.Errorf("between expects 2 values, got %d", len(values))
+		}
+		return clause.Expr{SQL: "? BETWEEN ? AND ?", Vars: []interface{}{column, values[0], values[1]}}, nil
+	case FilterIsNull:
+		isNull, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("isnull expects a bool, got %T", value)
+		}
+		if isNull {
+			return clause.Eq{Column: column, Value: nil}, nil
+		}
+		return clause.Neq{Column: column, Value: nil}, nil
+	default:
+		return nil, fmt.Errorf("unsupported filter operator %q", operator)
+	}
+}
+
+// likeEscaper escapes the wildcards of LIKE patterns, for the values to be matched literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func escapeLike(value interface{}) string {
+	return likeEscaper.Replace(fmt.Sprint(value))
+}
+
+func likeExpression(column clause.Column, operator string, pattern string) clause.Expression {
+	return clause.Expr{SQL: "? " + operator + ` ? ESCAPE '\'`, Vars: []interface{}{column, pattern}}
+}
+
+func sliceValues(fieldValue reflect.Value) ([]interface{}, error) {
+	if fieldValue.Kind() != reflect.Slice && fieldValue.Kind() != reflect.Array {
+		return nil, fmt.Errorf("expected a slice, got %s", fieldValue.Type())
+	}
+	values := make([]interface{}, fieldValue.Len())
+	for i := range values {
+		values[i] = fieldValue.Index(i).Interface()
+	}
+	return values, nil
+}
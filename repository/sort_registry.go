@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/roksky/bootstrap-api/apperror"
+	"gorm.io/gorm"
+)
+
+// InvalidSortError is returned when an orderBy parameter names a field or a direction that is not allowed.
+type InvalidSortError struct {
+	Field   string
+	Allowed []string
+}
+
+func (e *InvalidSortError) Error() string {
+	return fmt.Sprintf("cannot sort on %q, allowed fields are %s", e.Field, strings.Join(e.Allowed, ", "))
+}
+
+func (e *InvalidSortError) AppError() *apperror.Error {
+	return apperror.Validation("invalid_sort", e.Error()).With("allowedFields", e.Allowed)
+}
+
+// SortRegistry maps the JSON field names clients may sort on to their database columns.
+type SortRegistry struct {
+	fields map[string]string
+}
+
+func NewSortRegistry(fields map[string]string) *SortRegistry {
+	return &SortRegistry{fields: fields}
+}
+
+// AllowedFields returns the sortable JSON field names in alphabetical order.
+func (r *SortRegistry) AllowedFields() []string {
+	allowed := make([]string, 0, len(r.fields))
+	for field := range r.fields {
+		allowed = append(allowed, field)
+	}
+	sort.Strings(allowed)
+	return allowed
+}
+
+// Column returns the column of a sortable JSON field name.
+func (r *SortRegistry) Column(field string) (string, bool) {
+	column, ok := r.fields[field]
+	return column, ok
+}
+
+// Parse validates an orderBy parameter of the form "field:direction,field" and resolves its columns.
+// The direction is asc or desc and defaults to asc.
+func (r *SortRegistry) Parse(orderBy string) ([]SortField, error) {
+	var fields []SortField
+	for _, part := range strings.Split(orderBy, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, direction, _ := strings.Cut(part, ":")
+		column, ok := r.fields[strings.TrimSpace(name)]
+		if !ok {
+			return nil, &InvalidSortError{Field: name, Allowed: r.AllowedFields()}
+		}
+
+		switch strings.ToLower(strings.TrimSpace(direction)) {
+		case "", "asc":
+			fields = append(fields, SortField{Column: column})
+		case "desc":
+			fields = append(fields, SortField{Column: column, Desc: true})
+		default:
+			return nil, &InvalidSortError{Field: part, Allowed: r.AllowedFields()}
+		}
+	}
+	return fields, nil
+}
+
+// Sortable is implemented by search structs that restrict the fields their entity can be sorted on.
+type Sortable interface {
+	SortRegistry() *SortRegistry
+}
+
+// SortRegistryOf returns the sort registry declared by the search struct, or one allowing every
+// column of T under its JSON name when the search struct is not Sortable, see ModelSortRegistry.
+func SortRegistryOf[T any, S any](db *gorm.DB, searchParams *S) *SortRegistry {
+	if searchParams == nil {
+		searchParams = new(S)
+	}
+	if sortable, ok := any(searchParams).(Sortable); ok {
+		return sortable.SortRegistry()
+	}
+	return ModelSortRegistry[T](db)
+}
+
+type modelSortRegistryKey struct {
+	modelType reflect.Type
+	config    *gorm.Config
+}
+
+var modelSortRegistries sync.Map
+
+// ModelSortRegistry returns a registry of every column of T, keyed by the JSON name of its field.
+// The columns are named by the naming strategy of db, the default one when db is nil.
+func ModelSortRegistry[T any](db *gorm.DB) *SortRegistry {
+	key := modelSortRegistryKey{modelType: reflect.TypeOf(new(T)).Elem()}
+	if db != nil {
+		key.config = db.Config
+	}
+	if registry, ok := modelSortRegistries.Load(key); ok {
+		return registry.(*SortRegistry)
+	}
+	registry := NewSortRegistry(ModelColumns[T](db))
+	modelSortRegistries.Store(key, registry)
+	return registry
+}
+
+// ModelColumns maps the JSON name of every persisted field of T to its column, named by the naming
+// strategy of db, the default one when db is nil.
+func ModelColumns[T any](db *gorm.DB) map[string]string {
+	modelSchema, err := schemaOf(db, new(T))
+	if err != nil {
+		return map[string]string{}
+	}
+
+	columns := make(map[string]string, len(modelSchema.Fields))
+	for _, field := range modelSchema.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		name, _, _ := strings.Cut(field.StructField.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		columns[name] = field.DBName
+	}
+	return columns
+}
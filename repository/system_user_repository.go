@@ -1,47 +1,94 @@
 package repository
 
 import (
+	"errors"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/roksky/bootstrap-api/model"
 	"gorm.io/gorm"
 )
 
+type SystemUserRepository struct {
+	*GormRepository[model.SystemUser, uuid.UUID, SystemUserSearch]
+}
+
+func NewSystemUserRepository(Db *gorm.DB) BaseRepository[model.SystemUser, uuid.UUID, SystemUserSearch] {
+	repository := NewGormRepository[model.SystemUser, uuid.UUID, SystemUserSearch](Db, nil)
+	repository.IdColumn = "user_id"
+	return &SystemUserRepository{
+		GormRepository: repository,
+	}
+}
+
+type SystemUserSearch struct {
+	PageRequest
+	UserName            string      `query:"userName" filter:"column=user_name"`
+	UserNamePrefix      string      `query:"userNamePrefix" filter:"column=user_name,op=prefix"`
+	PrimaryOrganization uuid.UUID   `query:"primaryOrganization" filter:"column=primary_organization"`
+	DateCreated         []time.Time `query:"dateCreated" filter:"column=date_created,op=between"`
+	Active              *bool       `query:"active" filter:"column=active"`
+}
+
+var systemUserSortFields = NewSortRegistry(map[string]string{
+	"userName":    "user_name",
+	"dateCreated": "date_created",
+	"dateUpdated": "date_updated",
+})
+
+func (s *SystemUserSearch) SortRegistry() *SortRegistry {
+	return systemUserSortFields
+}
+
+// SystemUserRepo is the former system user repository.
+//
+// Deprecated: use NewSystemUserRepository.
 type SystemUserRepo struct {
-	db *gorm.DB
+	users BaseRepository[model.SystemUser, uuid.UUID, SystemUserSearch]
 }
 
+// NewSystemUserRepo creates a SystemUserRepo.
+//
+// Deprecated: use NewSystemUserRepository.
 func NewSystemUserRepo(db *gorm.DB) *SystemUserRepo {
 	return &SystemUserRepo{
-		db: db,
+		users: NewSystemUserRepository(db),
 	}
 }
 
+// Repository returns the repository backing m.
+func (m *SystemUserRepo) Repository() BaseRepository[model.SystemUser, uuid.UUID, SystemUserSearch] {
+	return m.users
+}
+
 func (m *SystemUserRepo) Save(item *model.SystemUser) error {
-	result := m.db.Create(item)
-	return result.Error
+	_, err := m.users.Save(nil, nil, item)
+	return err
 }
 
 func (m *SystemUserRepo) Delete(item *model.SystemUser) error {
-	result := m.db.Delete(item)
-	return result.Error
+	return m.users.Delete(nil, nil, item.UserId)
 }
 
+// FindByUserName returns an empty user when no user has the given name.
 func (m *SystemUserRepo) FindByUserName(userName string) (*model.SystemUser, error) {
-	var systemUser model.SystemUser
-	result := m.db.Where("user_name = ?", userName).Find(&systemUser)
-	if result.Error == nil {
-		return &systemUser, nil
-	} else {
-		return nil, result.Error
+	search := &SystemUserSearch{UserName: userName}
+	search.PageSize = 1
+	systemUsers, err := m.users.Search(nil, search)
+	if err != nil {
+		return nil, err
+	}
+	if len(systemUsers) == 0 {
+		return &model.SystemUser{}, nil
 	}
+	return systemUsers[0], nil
 }
 
+// FindById returns an empty user when no user has the given id.
 func (m *SystemUserRepo) FindById(userId uuid.UUID) (*model.SystemUser, error) {
-	var systemUser model.SystemUser
-	result := m.db.Where("user_id = ?", userId).Find(&systemUser)
-	if result.Error == nil {
-		return &systemUser, nil
-	} else {
-		return nil, result.Error
+	systemUser, err := m.users.FindById(nil, nil, userId)
+	if errors.Is(err, ErrNotFound) {
+		return &model.SystemUser{}, nil
 	}
+	return systemUser, err
 }
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/google/uuid"
+	"github.com/roksky/bootstrap-api/apperror"
+	"github.com/roksky/bootstrap-api/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ErrTenantRequired is returned when a tenant-owned model is accessed without an organization in a
+// context requiring one, see RequireTenant.
+var ErrTenantRequired = apperror.Forbidden("tenant_required", "no organization is selected for the request")
+
+// ErrCrossTenant is returned when a write targets an organization other than the one of the request.
+var ErrCrossTenant = apperror.Forbidden("cross_tenant", "entity belongs to another organization")
+
+type tenantKey struct{}
+
+type tenantUnscopedKey struct{}
+
+type tenantRequiredKey struct{}
+
+// WithTenant returns a copy of ctx restricting tenant-owned models to the given organization.
+func WithTenant(ctx context.Context, organizationId uuid.UUID) context.Context {
+	return context.WithValue(ctx, tenantKey{}, organizationId)
+}
+
+// TenantFromContext returns the organization set by WithTenant.
+func TenantFromContext(ctx context.Context) (uuid.UUID, bool) {
+	if ctx == nil {
+		return uuid.Nil, false
+	}
+	organizationId, ok := ctx.Value(tenantKey{}).(uuid.UUID)
+	return organizationId, ok
+}
+
+// RequireTenant returns a copy of ctx denying the tenant-owned models, with ErrTenantRequired, unless
+// an organization is set with WithTenant. It marks the contexts of the requests subject to the tenant
+// scope, see router.TenantMiddleware.
+func RequireTenant(ctx context.Context) context.Context {
+	return context.WithValue(ctx, tenantRequiredKey{}, true)
+}
+
+// WithoutTenantScope returns a copy of ctx giving access to the models of every organization,
+// for system tasks such as resolving the memberships of a user or registering an organization.
+func WithoutTenantScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, tenantUnscopedKey{}, true)
+}
+
+// RegisterTenantScope restricts the statements on the model.TenantOwned models to the organization
+// of their context, see WithTenant. Contexts without organization are not scoped unless marked by
+// RequireTenant, and raw SQL never is. Organizations themselves can be created by anyone.
+func RegisterTenantScope(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Create().Before("gorm:create").Register("tenant:create", assignTenant); err != nil {
+		return err
+	}
+	if err := callbacks.Query().Before("gorm:query").Register("tenant:query", scopeToTenant); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("gorm:row").Register("tenant:row", scopeToTenant); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:update").Register("tenant:update", scopeToTenant); err != nil {
+		return err
+	}
+	return callbacks.Delete().Before("gorm:delete").Register("tenant:delete", scopeToTenant)
+}
+
+// scopeToTenant filters the statement on the tenant of its context, and rejects values moving
+// an entity to another tenant.
+func scopeToTenant(db *gorm.DB) {
+	field, organizationId, ok := statementTenant(db)
+	if !ok {
+		return
+	}
+	if err := checkTenant(db, field, organizationId, false); err != nil {
+		db.AddError(err)
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: organizationId},
+	}})
+}
+
+// assignTenant fills the tenant column of created entities and rejects those of another tenant.
+func assignTenant(db *gorm.DB) {
+	// an entity being its own tenant is created outside of the scope of any other
+	if field := tenantField(db); field != nil && field.PrimaryKey {
+		return
+	}
+	field, organizationId, ok := statementTenant(db)
+	if !ok {
+		return
+	}
+	if err := checkTenant(db, field, organizationId, true); err != nil {
+		db.AddError(err)
+	}
+}
+
+// tenantField returns the tenant field of the statement model, nil when it is not tenant-owned.
+func tenantField(db *gorm.DB) *schema.Field {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return nil
+	}
+	owned, isOwned := reflect.New(db.Statement.Schema.ModelType).Interface().(model.TenantOwned)
+	if !isOwned {
+		return nil
+	}
+	return db.Statement.Schema.LookUpField(owned.TenantColumn())
+}
+
+// statementTenant returns the tenant field of the statement model and the organization to restrict
+// it to, ok being false when the statement is not to be scoped.
+func statementTenant(db *gorm.DB) (*schema.Field, uuid.UUID, bool) {
+	field := tenantField(db)
+	if field == nil {
+		return nil, uuid.Nil, false
+	}
+
+	ctx := db.Statement.Context
+	if unscoped, _ := ctx.Value(tenantUnscopedKey{}).(bool); unscoped {
+		return nil, uuid.Nil, false
+	}
+	organizationId, hasTenant := TenantFromContext(ctx)
+	if !hasTenant {
+		if required, _ := ctx.Value(tenantRequiredKey{}).(bool); required {
+			db.AddError(ErrTenantRequired)
+		}
+		return nil, uuid.Nil, false
+	}
+	return field, organizationId, true
+}
+
+// checkTenant verifies the tenant of the entities held by the statement. When assign is true, a
+// missing tenant is filled in.
+func checkTenant(db *gorm.DB, field *schema.Field, organizationId uuid.UUID, assign bool) error {
+	check := func(value reflect.Value) error {
+		value = reflect.Indirect(value)
+		if value.Kind() != reflect.Struct {
+			return nil
+		}
+		current, isZero := field.ValueOf(db.Statement.Context, value)
+		if isZero {
+			if !assign {
+				return nil
+			}
+			if field.FieldType.Kind() == reflect.Ptr {
+				return field.Set(db.Statement.Context, value, &organizationId)
+			}
+			return field.Set(db.Statement.Context, value, organizationId)
+		}
+		if pointer, ok := current.(*uuid.UUID); ok {
+			current = *pointer
+		}
+		if current != organizationId {
+			return ErrCrossTenant
+		}
+		return nil
+	}
+
+	value := db.Statement.ReflectValue
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := check(value.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return check(value)
+	}
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// searchVectorColumn is the generated tsvector column of the models having searchable fields.
+const searchVectorColumn = "search_vector"
+
+// textSearchConfig is the Postgres text search configuration. simple does not stem words, so
+// that names match as they are typed.
+const textSearchConfig = "simple"
+
+// TextSearch adds full-text search to a search struct: embed it to expose the q query parameter.
+// The words of q match the fields of the model tagged with `search`, as prefixes, and results are
+// ranked by relevance unless the search sets orderBy.
+type TextSearch struct {
+	Q string `query:"q"`
+}
+
+// GetTextSearch returns the text search of a search struct embedding TextSearch.
+func (t *TextSearch) GetTextSearch() *TextSearch {
+	return t
+}
+
+// TextSearchable is implemented by every search struct that embeds TextSearch.
+type TextSearchable interface {
+	GetTextSearch() *TextSearch
+}
+
+// searchableColumn is a column tagged with `search:"weight"`, the weight being A, B, C or D.
+type searchableColumn struct {
+	name   string
+	weight string
+}
+
+// MigrateTextSearch adds the generated search_vector column and its GIN index to the table of model,
+// when the model has fields tagged with `search`, e.g. `search:"A"` for a field of the highest weight.
+// The column is only created once: drop it for a change of the searchable fields to be applied.
+func MigrateTextSearch(db *gorm.DB, model any) error {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return err
+	}
+	columns := searchableColumns(stmt.Schema)
+	if len(columns) == 0 {
+		return nil
+	}
+
+	vectors := make([]string, 0, len(columns))
+	for _, column := range columns {
+		vectors = append(vectors, fmt.Sprintf("setweight(to_tsvector('%s', coalesce(%s::text, '')), '%s')",
+			textSearchConfig, db.Statement.Quote(column.name), column.weight))
+	}
+	table := db.Statement.Quote(stmt.Schema.Table)
+	vectorColumn := db.Statement.Quote(searchVectorColumn)
+
+	err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s tsvector GENERATED ALWAYS AS (%s) STORED",
+		table, vectorColumn, strings.Join(vectors, " || "))).Error
+	if err != nil {
+		return err
+	}
+	return db.Exec(fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s USING GIN (%s)",
+		db.Statement.Quote("idx_"+stmt.Schema.Table+"_"+searchVectorColumn), table, vectorColumn)).Error
+}
+
+func searchableColumns(modelSchema *schema.Schema) []searchableColumn {
+	var columns []searchableColumn
+	for _, field := range modelSchema.Fields {
+		weight, ok := field.StructField.Tag.Lookup("search")
+		if !ok || field.DBName == "" {
+			continue
+		}
+		weight = strings.ToUpper(strings.TrimSpace(weight))
+		switch weight {
+		case "A", "B", "C", "D":
+		default:
+			weight = "D"
+		}
+		columns = append(columns, searchableColumn{name: field.DBName, weight: weight})
+	}
+	return columns
+}
+
+var textSearchModels sync.Map
+
+// isTextSearchable tells whether T has fields tagged with `search`.
+func isTextSearchable[T any](db *gorm.DB) bool {
+	modelType := reflect.TypeOf(new(T)).Elem()
+	if searchable, ok := textSearchModels.Load(modelType); ok {
+		return searchable.(bool)
+	}
+	modelSchema, err := schemaOf(db, new(T))
+	searchable := err == nil && len(searchableColumns(modelSchema)) > 0
+	textSearchModels.Store(modelType, searchable)
+	return searchable
+}
+
+// textQueryOf returns the tsquery matching the words of the q parameter of searchParams as
+// prefixes, empty when there is none.
+func textQueryOf(searchParams any) string {
+	textSearchable, ok := searchParams.(TextSearchable)
+	if !ok {
+		return ""
+	}
+	words := strings.FieldsFunc(textSearchable.GetTextSearch().Q, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		terms = append(terms, strings.ToLower(word)+":*")
+	}
+	return strings.Join(terms, " & ")
+}
+
+func tsQuery(query string) clause.Expr {
+	return clause.Expr{SQL: "to_tsquery(?, ?)", Vars: []interface{}{textSearchConfig, query}}
+}
+
+// applyTextSearch restricts db to the rows matching the text query.
+func applyTextSearch(db *gorm.DB, query string) *gorm.DB {
+	return db.Where("? @@ ?", filterColumn(searchVectorColumn), tsQuery(query))
+}
+
+// rankByRelevance orders db by decreasing relevance to the text query.
+func rankByRelevance(db *gorm.DB, query string) *gorm.DB {
+	return db.Order(clause.OrderBy{Expression: clause.Expr{
+		SQL:                "ts_rank(?, ?) DESC",
+		Vars:               []interface{}{filterColumn(searchVectorColumn), tsQuery(query)},
+		WithoutParentheses: true,
+	}})
+}
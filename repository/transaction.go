@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type transactionKey struct{}
+
+// WithTransaction runs fn in a transaction carried by its context, which the services and
+// repositories bound to it join. It is rolled back when fn fails or panics.
+// A nested call runs in a savepoint of the outer transaction.
+func WithTransaction(ctx context.Context, db *gorm.DB, fn func(ctx context.Context) error) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if tx := TxFromContext(ctx); tx != nil {
+		db = tx
+	}
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, transactionKey{}, tx))
+	})
+}
+
+// TxFromContext returns the transaction started by WithTransaction, or nil outside of one.
+func TxFromContext(ctx context.Context) *gorm.DB {
+	if ctx == nil {
+		return nil
+	}
+	tx, _ := ctx.Value(transactionKey{}).(*gorm.DB)
+	return tx
+}
+
+// inTransaction runs fn with db when it is a transaction, and with a new one otherwise, see
+// WithTransaction, for the statements of a write to be committed together.
+func inTransaction(db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	if committer, ok := db.Statement.ConnPool.(gorm.TxCommitter); ok && committer != nil {
+		return fn(db)
+	}
+	return WithTransaction(db.Statement.Context, db, func(ctx context.Context) error {
+		return fn(TxFromContext(ctx))
+	})
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"github.com/roksky/bootstrap-api/model"
+	"gorm.io/gorm"
+)
+
+type UserPreferencesRepository struct {
+	*GormRepository[model.UserPreferences, uuid.UUID, UserPreferencesSearch]
+}
+
+func NewUserPreferencesRepository(Db *gorm.DB) BaseRepository[model.UserPreferences, uuid.UUID, UserPreferencesSearch] {
+	return &UserPreferencesRepository{
+		GormRepository: NewGormRepository[model.UserPreferences, uuid.UUID, UserPreferencesSearch](Db, nil),
+	}
+}
+
+type UserPreferencesSearch struct {
+	PageRequest
+	SystemUserId uuid.UUID `query:"systemUser" filter:"column=system_user"`
+}
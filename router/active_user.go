@@ -0,0 +1,51 @@
+package router
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/roksky/bootstrap-api/apperror"
+	"github.com/roksky/bootstrap-api/controller"
+	"github.com/roksky/bootstrap-api/model"
+	"github.com/roksky/bootstrap-api/repository"
+)
+
+// UserRepository gives access to the system users.
+type UserRepository = repository.BaseRepository[model.SystemUser, uuid.UUID, repository.SystemUserSearch]
+
+// ErrUserInactive is returned for the requests of a user deactivated by an administrator.
+var ErrUserInactive = apperror.Forbidden("user_inactive", "user is deactivated")
+
+// ActiveUserMiddleware rejects the requests of the system users deactivated by an administrator,
+// see model.SystemUser. The tokens of users who are not registered system users are left to the
+// other checks.
+func ActiveUserMiddleware(users UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenInfo, err := controller.GetTokenInfo(c)
+		if err != nil {
+			controller.RespondError(c, err)
+			return
+		}
+		userId, err := uuid.Parse(tokenInfo.GetUserID())
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		user, err := repository.Bind(users, c.Request.Context()).FindById(nil, nil, userId)
+		if errors.Is(err, repository.ErrNotFound) {
+			c.Next()
+			return
+		}
+		if err != nil {
+			controller.RespondError(c, err)
+			return
+		}
+		if user.Active != nil && !*user.Active {
+			controller.RespondError(c, ErrUserInactive)
+			return
+		}
+		c.Next()
+	}
+}
@@ -13,8 +13,11 @@ import (
 	"github.com/go-oauth2/oauth2/v4"
 	"github.com/go-oauth2/oauth2/v4/manage"
 	"github.com/go-oauth2/oauth2/v4/server"
+	"github.com/google/uuid"
+	"github.com/roksky/bootstrap-api/apperror"
 	"github.com/roksky/bootstrap-api/constants"
 	"github.com/roksky/bootstrap-api/controller"
+	"github.com/roksky/bootstrap-api/repository"
 )
 
 type RouteHandler interface {
@@ -24,6 +27,9 @@ type RouteHandler interface {
 	EnableAuth(introspectURL string, clientId string, clientSecret string) error
 	AllowCORS()
 	EnableSentry(dsn string)
+	SetRequestTimeout(timeout time.Duration)
+	EnableTenantScoping(memberships MembershipRepository) error
+	RejectInactiveUsers(users UserRepository)
 }
 
 type Router struct {
@@ -31,6 +37,12 @@ type Router struct {
 	engine      *gin.Engine
 	server      *server.Server
 	authEnabled bool
+	// requestTimeout applies to the routes without a timeout of their own, zero means none.
+	requestTimeout time.Duration
+	// tenantMiddleware resolves the organization of the requests to authenticated routes, when tenant scoping is enabled.
+	tenantMiddleware gin.HandlerFunc
+	// activeUserMiddleware rejects the requests of deactivated users to authenticated routes, when enabled.
+	activeUserMiddleware gin.HandlerFunc
 }
 
 func NewRouteHandler(baseUrl string) (RouteHandler, error) {
@@ -71,13 +83,20 @@ func (r *Router) RegisterRoute(cnt controller.Controller) {
 
 	if r.authEnabled && cnt.IsAuthEnabled() {
 		controllerRouter.Use(ginoauth2.HandleTokenVerify(routerConfig))
+		if r.activeUserMiddleware != nil {
+			controllerRouter.Use(r.activeUserMiddleware)
+		}
+		if r.tenantMiddleware != nil {
+			controllerRouter.Use(r.tenantMiddleware)
+		}
 	}
+	controllerRouter.Use(requestContext)
 
 	// Middleware to enforce Bearer token validation
 	authMiddleware := func(c *gin.Context) {
 		ti, err := r.server.ValidationBearerToken(c.Request)
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			controller.RespondError(c, apperror.Wrap(apperror.KindUnauthorized, "invalid_token", err))
 			return
 		}
 		// you can pull claims from ti.GetUserID(), ti.GetScope(), etc.
@@ -87,21 +106,79 @@ func (r *Router) RegisterRoute(cnt controller.Controller) {
 	baseRouter.Use(authMiddleware)
 
 	for _, route := range cnt.Handlers() {
+		handlers := []gin.HandlerFunc{route.GetHandlerFunc()}
+		timeout := route.GetTimeout()
+		if timeout == 0 {
+			timeout = r.requestTimeout
+		}
+		if timeout > 0 {
+			handlers = append([]gin.HandlerFunc{requestTimeout(timeout)}, handlers...)
+		}
+
 		switch route.GetHttpMethod() {
 		case controller.GET:
-			controllerRouter.GET(route.GetUrlTemplate(), route.GetHandlerFunc())
+			controllerRouter.GET(route.GetUrlTemplate(), handlers...)
 		case controller.POST:
-			controllerRouter.POST(route.GetUrlTemplate(), route.GetHandlerFunc())
+			controllerRouter.POST(route.GetUrlTemplate(), handlers...)
 		case controller.PUT:
-			controllerRouter.PUT(route.GetUrlTemplate(), route.GetHandlerFunc())
+			controllerRouter.PUT(route.GetUrlTemplate(), handlers...)
 		case controller.PATCH:
-			controllerRouter.PATCH(route.GetUrlTemplate(), route.GetHandlerFunc())
+			controllerRouter.PATCH(route.GetUrlTemplate(), handlers...)
 		case controller.DELETE:
-			controllerRouter.DELETE(route.GetUrlTemplate(), route.GetHandlerFunc())
+			controllerRouter.DELETE(route.GetUrlTemplate(), handlers...)
 		}
 	}
 }
 
+// SetRequestTimeout bounds the duration of the requests of every route registered afterwards,
+// unless the route sets a timeout of its own.
+func (r *Router) SetRequestTimeout(timeout time.Duration) {
+	r.requestTimeout = timeout
+}
+
+// EnableTenantScoping restricts the queries of tenant-owned models to the organization of the token
+// user on the authenticated routes registered afterwards, see TenantMiddleware.
+func (r *Router) EnableTenantScoping(memberships MembershipRepository) error {
+	if err := repository.RegisterTenantScope(memberships.GetDB()); err != nil {
+		return err
+	}
+	r.tenantMiddleware = TenantMiddleware(memberships)
+	return nil
+}
+
+// RejectInactiveUsers denies the authenticated routes registered afterwards to the users deactivated
+// by an administrator, see ActiveUserMiddleware.
+func (r *Router) RejectInactiveUsers(users UserRepository) {
+	r.activeUserMiddleware = ActiveUserMiddleware(users)
+}
+
+// requestContext stores the request id and the token user in the request context, where the
+// repositories find them for the audit log.
+func requestContext(c *gin.Context) {
+	requestId := c.GetHeader(constants.RequestIdHeader)
+	if requestId == "" {
+		requestId = uuid.NewString()
+	}
+	c.Header(constants.RequestIdHeader, requestId)
+
+	ctx := repository.WithRequestId(c.Request.Context(), requestId)
+	if tokenInfo, err := controller.GetTokenInfo(c); err == nil {
+		ctx = repository.WithActor(ctx, tokenInfo.GetUserID())
+	}
+	c.Request = c.Request.WithContext(ctx)
+	c.Next()
+}
+
+// requestTimeout cancels the request context after timeout, cancelling the queries made with it.
+func requestTimeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
 func (r *Router) GetEngine() *gin.Engine {
 	return r.engine
 }
@@ -110,7 +187,8 @@ func (r *Router) AllowCORS() {
 	r.engine.Use(func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, If-Match, If-None-Match")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "ETag")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, HEAD, OPTIONS")
 
 		// Handle preflight requests
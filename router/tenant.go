@@ -0,0 +1,89 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/roksky/bootstrap-api/apperror"
+	"github.com/roksky/bootstrap-api/constants"
+	"github.com/roksky/bootstrap-api/controller"
+	"github.com/roksky/bootstrap-api/model"
+	"github.com/roksky/bootstrap-api/repository"
+)
+
+// maxMemberships bounds the number of memberships looked up for the user of a request.
+const maxMemberships = 1000
+
+// MembershipRepository gives access to the organizations users belong to.
+type MembershipRepository = repository.BaseRepository[model.SystemUserOrganization, uuid.UUID, repository.SystemUserOrganizationSearch]
+
+// TenantMiddleware stores the active organization of the token user in the request context: the
+// one of the X-Organization-Id header, then the primary one, then the only membership of the user.
+// Users who are not a member of it are rejected.
+func TenantMiddleware(memberships MembershipRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenInfo, err := controller.GetTokenInfo(c)
+		if err != nil {
+			controller.RespondError(c, err)
+			return
+		}
+		userId, err := uuid.Parse(tokenInfo.GetUserID())
+		if err != nil {
+			controller.RespondError(c, apperror.Forbidden("not_system_user", "token user is not a system user"))
+			return
+		}
+
+		search := &repository.SystemUserOrganizationSearch{SystemUser: userId.String()}
+		search.PageSize = maxMemberships
+		search.Include = "systemUser"
+		ctx := repository.WithoutTenantScope(c.Request.Context())
+		userMemberships, err := repository.Bind(memberships, ctx).Search(nil, search)
+		if err != nil {
+			controller.RespondError(c, err)
+			return
+		}
+
+		organizationId, err := activeOrganization(c.GetHeader(constants.OrganizationHeader), userMemberships)
+		if err != nil {
+			controller.RespondError(c, err)
+			return
+		}
+
+		ctx = repository.RequireTenant(c.Request.Context())
+		if organizationId != uuid.Nil {
+			ctx = repository.WithTenant(ctx, organizationId)
+		}
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// activeOrganization returns the organization selected by the requested header among memberships,
+// uuid.Nil for a user without membership.
+func activeOrganization(requested string, memberships []*model.SystemUserOrganization) (uuid.UUID, error) {
+	isMember := func(organizationId uuid.UUID) bool {
+		for _, membership := range memberships {
+			if membership.OrganizationId == organizationId {
+				return true
+			}
+		}
+		return false
+	}
+
+	if requested != "" {
+		organizationId, err := uuid.Parse(requested)
+		if err != nil || !isMember(organizationId) {
+			return uuid.Nil, apperror.Forbidden("not_member", "user is not a member of the requested organization")
+		}
+		return organizationId, nil
+	}
+	if len(memberships) == 0 {
+		return uuid.Nil, nil
+	}
+	if primary := memberships[0].SystemUser.PrimaryOrganization; primary != uuid.Nil && isMember(primary) {
+		return primary, nil
+	}
+	if len(memberships) == 1 {
+		return memberships[0].OrganizationId, nil
+	}
+	return uuid.Nil, apperror.Forbidden("organization_required", "user belongs to several organizations, select one with the "+constants.OrganizationHeader+" header")
+}
@@ -0,0 +1,18 @@
+package service
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/roksky/bootstrap-api/model"
+	"github.com/roksky/bootstrap-api/repository"
+)
+
+type AuditLogService struct {
+	*CrudService[model.AuditLog, uuid.UUID, repository.AuditLogSearch]
+}
+
+func NewAuditLogService(repository repository.BaseRepository[model.AuditLog, uuid.UUID, repository.AuditLogSearch], validate *validator.Validate) BaseService[model.AuditLog, uuid.UUID, repository.AuditLogSearch] {
+	return &AuditLogService{
+		CrudService: NewCrudService(repository, validate),
+	}
+}
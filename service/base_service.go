@@ -1,11 +1,17 @@
 package service
 
-import "github.com/roksky/bootstrap-api/data/response"
+import (
+	"context"
+
+	"github.com/roksky/bootstrap-api/data/response"
+	"github.com/roksky/bootstrap-api/repository"
+)
 
 // BaseService defines a generic interface for basic CRUD operations.
 // T represents the type of the item.
 // K represents the type of the item's identifier.
 // S represents the type of the search parameters.
+// The other operations of a service are optional interfaces, such as CursorService.
 type BaseService[T any, K comparable, S any] interface {
 	// Create adds a new item.
 	// filterContext provides additional context for the operation.
@@ -13,7 +19,7 @@ type BaseService[T any, K comparable, S any] interface {
 	// Returns the created item and an error if any.
 	Create(filterContext *S, item *T) (*T, error)
 
-	// CreateMany adds multiple new items.
+	// CreateMany adds multiple new items, all of them or none.
 	// filterContext provides additional context for the operation.
 	// items is the list of items to be created.
 	// Returns the list of created items and an error if any.
@@ -23,9 +29,10 @@ type BaseService[T any, K comparable, S any] interface {
 	// filterContext provides additional context for the operation.
 	// item is the item to be updated.
 	// Returns the updated item and an error if any.
+	// A *repository.VersionConflictError is returned when the item version is stale.
 	Update(filterContext *S, item *T) (*T, error)
 
-	// UpdateMany modifies multiple existing items.
+	// UpdateMany modifies multiple existing items, all of them or none.
 	// filterContext provides additional context for the operation.
 	// items is the list of items to be updated.
 	// Returns the list of updated items and an error if any.
@@ -37,7 +44,7 @@ type BaseService[T any, K comparable, S any] interface {
 	// Returns an error if any.
 	Delete(searchParams *S, id K) error
 
-	// DeleteMany removes multiple items by their identifiers.
+	// DeleteMany removes multiple items by their identifiers, all of them or none.
 	// searchParams provides the search parameters.
 	// ids is the list of identifiers of the items to be deleted.
 	// Returns an error if any.
@@ -72,3 +79,99 @@ type BaseService[T any, K comparable, S any] interface {
 	// Returns the list of identifiers of deleted items and an error if any.
 	Deleted(searchParams *S) ([]string, error)
 }
+
+// ContextService is implemented by the services that can be bound to a context.
+type ContextService[T any, K comparable, S any] interface {
+	// WithContext returns the service bound to ctx.
+	// Operations of the bound service run in the transaction started on ctx by repository.WithTransaction, if any,
+	// and their queries are cancelled along with ctx.
+	WithContext(ctx context.Context) BaseService[T, K, S]
+}
+
+// EachService is implemented by the services writing the items of a bulk operation one by one.
+type EachService[T any, K comparable, S any] interface {
+	// CreateEach adds multiple new items one by one, each in its own transaction, so that the
+	// failure of an item does not prevent the others from being created.
+	// filterContext provides additional context for the operation.
+	// items is the list of items to be created.
+	// Returns the outcome of each item, in the order of items.
+	CreateEach(filterContext *S, items []*T) []ItemResult[T]
+
+	// UpdateEach modifies multiple existing items one by one, each in its own transaction.
+	// filterContext provides additional context for the operation.
+	// items is the list of items to be updated.
+	// Returns the outcome of each item, in the order of items.
+	UpdateEach(filterContext *S, items []*T) []ItemResult[T]
+
+	// DeleteEach removes multiple items by their identifiers one by one, each in its own transaction.
+	// searchParams provides the search parameters.
+	// ids is the list of identifiers of the items to be deleted.
+	// Returns the error of each item, nil for the deleted ones, in the order of ids.
+	DeleteEach(searchParams *S, ids []K) []error
+}
+
+// ReplaceService is implemented by the services that can overwrite an item.
+type ReplaceService[T any, K comparable, S any] interface {
+	// Replace overwrites an existing item, its zero values included, but its creation and deletion
+	// fields.
+	// filterContext provides additional context for the operation.
+	// item is the new state of the item.
+	// Returns the replaced item and an error if any.
+	// A *repository.VersionConflictError is returned when the item version is stale.
+	Replace(filterContext *S, item *T) (*T, error)
+}
+
+// RestoreService is implemented by the services that can restore and purge deleted items.
+type RestoreService[T any, K comparable, S any] interface {
+	// Restore undoes the deletion of items.
+	// searchParams provides the search parameters.
+	// ids is the list of identifiers of the deleted items to be restored.
+	// Returns an error if any.
+	Restore(searchParams *S, ids []K) error
+
+	// Purge permanently removes items, including deleted ones.
+	// searchParams provides the search parameters.
+	// ids is the list of identifiers of the items to be purged.
+	// Returns an error if any.
+	Purge(searchParams *S, ids []K) error
+}
+
+// CursorService is implemented by the services supporting keyset pagination.
+type CursorService[T any, K comparable, S any] interface {
+	// SearchByCursor performs a keyset paginated search based on the search parameters.
+	// searchParams provides the search parameters, including the cursor returned with the previous page.
+	// Returns a page of found items with the cursor of the next page and an error if any.
+	SearchByCursor(searchParams *S) (response.CursorResult[*T], error)
+}
+
+// ChangesService is implemented by the services feeding the changes of tracked items.
+type ChangesService[T any, K comparable, S any] interface {
+	// Changes retrieves the items upserted and deleted since a sync token, for offline clients.
+	// searchParams provides the search parameters.
+	// since is the sync token of the previous call, an RFC 3339 timestamp, or empty for every change.
+	// Returns the changes with the sync token to resume from and an error if any.
+	Changes(searchParams *S, since string) (response.ChangesResult[*T], error)
+}
+
+// StatsService is implemented by the services computing statistics over items.
+type StatsService[T any, K comparable, S any] interface {
+	// Stats computes statistics over the items matching the search parameters.
+	// searchParams provides the search parameters.
+	// request lists the fields to group the items on and the metrics to compute for each group.
+	// Returns the groups with their metrics and an error if any.
+	Stats(searchParams *S, request *repository.AggregateRequest) (response.StatsResult, error)
+}
+
+// Bind returns service bound to ctx when it is a ContextService, and service itself otherwise.
+func Bind[T any, K comparable, S any](service BaseService[T, K, S], ctx context.Context) BaseService[T, K, S] {
+	if contextual, ok := service.(ContextService[T, K, S]); ok && ctx != nil {
+		return contextual.WithContext(ctx)
+	}
+	return service
+}
+
+// ItemResult is the outcome of one item of a bulk operation: the written item, or the error that prevented it.
+type ItemResult[T any] struct {
+	Item *T
+	Err  error
+}
@@ -0,0 +1,406 @@
+package service
+
+import (
+	"context"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/roksky/bootstrap-api/apperror"
+	"github.com/roksky/bootstrap-api/data/response"
+	"github.com/roksky/bootstrap-api/model"
+	"github.com/roksky/bootstrap-api/repository"
+)
+
+// ErrIdMissing is returned when an operation needs the id of an entity that has none.
+var ErrIdMissing = apperror.Validation("id_missing", "entity id is missing")
+
+// ErrInvalidId is returned when an operation is given the zero value as an id.
+var ErrInvalidId = apperror.Validation("invalid_id", "invalid id")
+
+// CrudService is a generic implementation of BaseService on top of a BaseRepository.
+// Items are validated before being written, and ids are checked against the zero value of K.
+type CrudService[T any, K comparable, S any] struct {
+	repository repository.BaseRepository[T, K, S]
+	Validate   *validator.Validate
+	ctx        context.Context
+}
+
+// NewCrudService creates a CrudService validating the items with validate, apperror.Validator when
+// nil. The validation errors name the fields after their JSON name, see apperror.InvalidItem.
+func NewCrudService[T any, K comparable, S any](repository repository.BaseRepository[T, K, S], validate *validator.Validate) *CrudService[T, K, S] {
+	if validate == nil {
+		validate = apperror.Validator()
+	}
+	return &CrudService[T, K, S]{
+		repository: repository,
+		Validate:   validate,
+	}
+}
+
+// Repository returns the repository backing the service.
+func (e *CrudService[T, K, S]) Repository() repository.BaseRepository[T, K, S] {
+	return e.repository
+}
+
+// WithContext returns a copy of the service whose repository is bound to ctx and its transaction.
+func (e *CrudService[T, K, S]) WithContext(ctx context.Context) BaseService[T, K, S] {
+	bound := *e
+	bound.repository = repository.Bind(e.repository, ctx)
+	bound.ctx = ctx
+	return &bound
+}
+
+func (e *CrudService[T, K, S]) Create(filterContext *S, item *T) (*T, error) {
+	err := e.validate(item)
+	if err != nil {
+		return nil, err
+	}
+	var saved *T
+	err = e.inTransaction(func(repo repository.BaseRepository[T, K, S]) error {
+		var err error
+		saved, err = repo.Save(nil, filterContext, item)
+		return err
+	})
+	return saved, err
+}
+
+func (e *CrudService[T, K, S]) CreateMany(filterContext *S, items []*T) ([]*T, error) {
+	for _, item := range items {
+		err := e.validate(item)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var saved []*T
+	err := e.inTransaction(func(repo repository.BaseRepository[T, K, S]) error {
+		var err error
+		saved, err = repo.SaveMany(nil, filterContext, items)
+		return err
+	})
+	return saved, err
+}
+
+func (e *CrudService[T, K, S]) CreateEach(filterContext *S, items []*T) []ItemResult[T] {
+	results := make([]ItemResult[T], len(items))
+	for i, item := range items {
+		if err := e.validate(item); err != nil {
+			results[i].Err = err
+			continue
+		}
+		results[i].Err = e.inTransaction(func(repo repository.BaseRepository[T, K, S]) error {
+			var err error
+			results[i].Item, err = repo.Save(nil, filterContext, item)
+			return err
+		})
+	}
+	return results
+}
+
+func (e *CrudService[T, K, S]) Update(filterContext *S, item *T) (*T, error) {
+	err := e.validate(item)
+	if err != nil {
+		return nil, err
+	}
+	if isZeroId(idOf[T, K](item)) {
+		return nil, ErrIdMissing
+	}
+	var updated *T
+	err = e.inTransaction(func(repo repository.BaseRepository[T, K, S]) error {
+		var err error
+		updated, err = repo.Update(nil, filterContext, item)
+		return err
+	})
+	return updated, err
+}
+
+func (e *CrudService[T, K, S]) Replace(filterContext *S, item *T) (*T, error) {
+	err := e.validate(item)
+	if err != nil {
+		return nil, err
+	}
+	if isZeroId(idOf[T, K](item)) {
+		return nil, ErrIdMissing
+	}
+	var replaced *T
+	err = e.inTransaction(func(repo repository.BaseRepository[T, K, S]) error {
+		var err error
+		replacer, ok := repo.(repository.ReplaceRepository[T, K, S])
+		if !ok {
+			return repository.ErrUnsupported
+		}
+		replaced, err = replacer.Replace(nil, filterContext, item)
+		return err
+	})
+	return replaced, err
+}
+
+func (e *CrudService[T, K, S]) UpdateMany(filterContext *S, items []*T) ([]*T, error) {
+	for _, item := range items {
+		err := e.validate(item)
+		if err != nil {
+			return nil, err
+		}
+		if isZeroId(idOf[T, K](item)) {
+			return nil, ErrIdMissing
+		}
+	}
+
+	var updated []*T
+	err := e.inTransaction(func(repo repository.BaseRepository[T, K, S]) error {
+		var err error
+		updated, err = repo.UpdateMany(nil, filterContext, items)
+		return err
+	})
+	return updated, err
+}
+
+func (e *CrudService[T, K, S]) UpdateEach(filterContext *S, items []*T) []ItemResult[T] {
+	results := make([]ItemResult[T], len(items))
+	for i, item := range items {
+		if err := e.validate(item); err != nil {
+			results[i].Err = err
+			continue
+		}
+		if isZeroId(idOf[T, K](item)) {
+			results[i].Err = ErrIdMissing
+			continue
+		}
+		results[i].Err = e.inTransaction(func(repo repository.BaseRepository[T, K, S]) error {
+			var err error
+			results[i].Item, err = repo.Update(nil, filterContext, item)
+			return err
+		})
+	}
+	return results
+}
+
+func (e *CrudService[T, K, S]) Delete(filterContext *S, id K) error {
+	if isZeroId(id) {
+		return ErrIdMissing
+	}
+
+	return e.inTransaction(func(repo repository.BaseRepository[T, K, S]) error {
+		return repo.Delete(nil, filterContext, id)
+	})
+}
+
+func (e *CrudService[T, K, S]) DeleteMany(filterContext *S, ids []K) error {
+	for _, id := range ids {
+		if isZeroId(id) {
+			return ErrInvalidId
+		}
+	}
+
+	return e.inTransaction(func(repo repository.BaseRepository[T, K, S]) error {
+		return repo.DeleteByIds(nil, filterContext, ids)
+	})
+}
+
+func (e *CrudService[T, K, S]) DeleteEach(filterContext *S, ids []K) []error {
+	errs := make([]error, len(ids))
+	for i, id := range ids {
+		if isZeroId(id) {
+			errs[i] = ErrInvalidId
+			continue
+		}
+		errs[i] = e.inTransaction(func(repo repository.BaseRepository[T, K, S]) error {
+			return repo.Delete(nil, filterContext, id)
+		})
+	}
+	return errs
+}
+
+func (e *CrudService[T, K, S]) Restore(filterContext *S, ids []K) error {
+	for _, id := range ids {
+		if isZeroId(id) {
+			return ErrInvalidId
+		}
+	}
+
+	return e.inTransaction(func(repo repository.BaseRepository[T, K, S]) error {
+		restorer, ok := repo.(repository.RestoreRepository[T, K, S])
+		if !ok {
+			return repository.ErrUnsupported
+		}
+		return restorer.Restore(nil, filterContext, ids)
+	})
+}
+
+func (e *CrudService[T, K, S]) Purge(filterContext *S, ids []K) error {
+	for _, id := range ids {
+		if isZeroId(id) {
+			return ErrInvalidId
+		}
+	}
+
+	return e.inTransaction(func(repo repository.BaseRepository[T, K, S]) error {
+		restorer, ok := repo.(repository.RestoreRepository[T, K, S])
+		if !ok {
+			return repository.ErrUnsupported
+		}
+		return restorer.Purge(nil, filterContext, ids)
+	})
+}
+
+func (e *CrudService[T, K, S]) FindById(filterContext *S, id K) (*T, error) {
+	if isZeroId(id) {
+		return nil, ErrIdMissing
+	}
+	return e.repository.FindById(nil, filterContext, id)
+}
+
+func (e *CrudService[T, K, S]) FindByIds(filterContext *S, ids []K) ([]*T, error) {
+	for _, id := range ids {
+		if isZeroId(id) {
+			return nil, ErrInvalidId
+		}
+	}
+
+	return e.repository.FindByIds(nil, filterContext, ids)
+}
+
+func (e *CrudService[T, K, S]) FindAll(filterContext *S, pageSize int, page int) (response.PagedResult[*T], error) {
+	var result response.PagedResult[*T]
+
+	items, err := e.repository.FindAll(nil, filterContext, pageSize, page)
+	if err != nil {
+		return result, err
+	}
+
+	count, err := e.repository.Count(nil, filterContext)
+	if err != nil {
+		return result, err
+	}
+
+	result.Items = items
+	result.TotalItems = count
+	result.PageSize = int64(repository.BoundPageSize(pageSize))
+	result.PageNumber = page
+
+	return result, nil
+}
+
+func (e *CrudService[T, K, S]) Search(searchParams *S) (response.PagedResult[*T], error) {
+	var result response.PagedResult[*T]
+
+	items, err := e.repository.Search(nil, searchParams)
+	if err != nil {
+		return result, err
+	}
+
+	count, err := e.repository.Count(nil, searchParams)
+	if err != nil {
+		return result, err
+	}
+
+	pageRequest := pageRequestOf(searchParams)
+	result.Items = items
+	result.TotalItems = count
+	result.PageSize = int64(pageRequest.Limit())
+	result.PageNumber = pageRequest.PageNumber
+
+	return result, nil
+}
+
+func (e *CrudService[T, K, S]) SearchByCursor(searchParams *S) (response.CursorResult[*T], error) {
+	var result response.CursorResult[*T]
+
+	cursorRepository, ok := e.repository.(repository.CursorRepository[T, K, S])
+	if !ok {
+		return result, repository.ErrUnsupported
+	}
+	items, nextCursor, err := cursorRepository.SearchByCursor(nil, searchParams)
+	if err != nil {
+		return result, err
+	}
+
+	pageRequest := pageRequestOf(searchParams)
+	result.Items = items
+	result.PageSize = int64(pageRequest.Limit())
+	result.NextCursor = nextCursor
+
+	return result, nil
+}
+
+func (e *CrudService[T, K, S]) Changes(searchParams *S, since string) (response.ChangesResult[*T], error) {
+	var result response.ChangesResult[*T]
+
+	changesRepository, ok := e.repository.(repository.ChangesRepository[T, K, S])
+	if !ok {
+		return result, repository.ErrUnsupported
+	}
+	changes, err := changesRepository.Changes(nil, searchParams, since)
+	if err != nil {
+		return result, err
+	}
+
+	result.Upserted = changes.Upserted
+	result.Deleted = changes.Deleted
+	result.SyncToken = changes.SyncToken
+	result.HasMore = changes.HasMore
+
+	return result, nil
+}
+
+func (e *CrudService[T, K, S]) Stats(searchParams *S, request *repository.AggregateRequest) (response.StatsResult, error) {
+	var result response.StatsResult
+
+	aggregateRepository, ok := e.repository.(repository.AggregateRepository[T, K, S])
+	if !ok {
+		return result, repository.ErrUnsupported
+	}
+	groups, err := aggregateRepository.Aggregate(nil, searchParams, request)
+	if err != nil {
+		return result, err
+	}
+
+	result.Groups = groups
+	if result.Groups == nil {
+		result.Groups = []map[string]interface{}{}
+	}
+
+	return result, nil
+}
+
+func (e *CrudService[T, K, S]) Deleted(searchParams *S) ([]string, error) {
+	return e.repository.Deleted(nil, searchParams)
+}
+
+// validate checks item against its validate tags. The error lists the invalid fields, see apperror.FieldError.
+func (e *CrudService[T, K, S]) validate(item *T) error {
+	if err := e.Validate.Struct(item); err != nil {
+		return apperror.InvalidItem(item, err)
+	}
+	return nil
+}
+
+// inTransaction runs fn with the repository bound to a transaction, joining the one of the service
+// context if any, for each write to be atomic with its audit and history records.
+func (e *CrudService[T, K, S]) inTransaction(fn func(repo repository.BaseRepository[T, K, S]) error) error {
+	return repository.WithTransaction(e.ctx, e.repository.GetDB(), func(ctx context.Context) error {
+		return fn(repository.Bind(e.repository, ctx))
+	})
+}
+
+func pageRequestOf[S any](searchParams *S) repository.PageRequest {
+	if searchParams == nil {
+		return repository.PageRequest{}
+	}
+	if pageable, ok := any(searchParams).(repository.Pageable); ok {
+		return *pageable.GetPageRequest()
+	}
+	return repository.PageRequest{}
+}
+
+func idOf[T any, K comparable](item *T) K {
+	if identifiable, ok := any(item).(model.Identifiable[K]); ok {
+		return identifiable.GetId()
+	}
+	var zero K
+	return zero
+}
+
+func isZeroId[K comparable](id K) bool {
+	var zero K
+	return id == zero
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/roksky/bootstrap-api/model"
+	"github.com/roksky/bootstrap-api/repository"
+)
+
+// HistoryService gives access to the versions of entities keeping a history, see model.Historized.
+// CrudService implements it on top of a repository implementing repository.HistoryRepository.
+type HistoryService[T any, K comparable, S any] interface {
+	// Versions returns the versions of an item, oldest first, or only the one in effect at the given time.
+	Versions(id K, at *time.Time) ([]*model.EntityVersion, error)
+
+	// Version returns the given version of an item.
+	Version(id K, version int64) (*model.EntityVersion, error)
+
+	// Revert replaces an item with the content of one of its versions, going through Replace so that
+	// the item is validated and versioned as with any other write. Every field is restored, empty ones
+	// included, but for the fields maintained by the server, see model.ServerManaged.
+	// filterContext provides additional context for the operation.
+	Revert(filterContext *S, id K, version int64) (*T, error)
+}
+
+func (e *CrudService[T, K, S]) Versions(id K, at *time.Time) ([]*model.EntityVersion, error) {
+	history, ok := e.repository.(repository.HistoryRepository[T, K])
+	if !ok {
+		return nil, repository.ErrHistoryDisabled
+	}
+	return history.Versions(nil, id, at)
+}
+
+func (e *CrudService[T, K, S]) Version(id K, version int64) (*model.EntityVersion, error) {
+	history, ok := e.repository.(repository.HistoryRepository[T, K])
+	if !ok {
+		return nil, repository.ErrHistoryDisabled
+	}
+	return history.Version(nil, id, version)
+}
+
+func (e *CrudService[T, K, S]) Revert(filterContext *S, id K, version int64) (*T, error) {
+	entityVersion, err := e.Version(id, version)
+	if err != nil {
+		return nil, err
+	}
+	current, err := e.repository.FindById(nil, filterContext, id)
+	if err != nil {
+		return nil, err
+	}
+	item, err := Reverted[T, K](current, entityVersion)
+	if err != nil {
+		return nil, err
+	}
+	if auditable, ok := any(item).(model.Auditable); ok {
+		auditable.SetUpdatedBy(repository.ActorFromContext(e.ctx))
+	}
+	return e.Replace(filterContext, item)
+}
+
+// Reverted returns the item replacing current to revert it to entityVersion, see HistoryService.Revert.
+func Reverted[T any, K comparable](current *T, entityVersion *model.EntityVersion) (*T, error) {
+	item := new(T)
+	if err := json.Unmarshal(entityVersion.Snapshot, item); err != nil {
+		return nil, err
+	}
+	// the replace applies on top of the current version, by the user reverting, and neither
+	// restores the timestamps of the version nor its deletion
+	if serverManaged, ok := any(item).(model.ServerManaged); ok {
+		serverManaged.ClearServerFields()
+	}
+	if identifiable, ok := any(current).(model.Identifiable[K]); ok {
+		any(item).(model.Identifiable[K]).SetId(identifiable.GetId())
+	}
+	if versioned, ok := any(current).(model.Versioned); ok {
+		any(item).(model.Versioned).SetVersion(versioned.GetVersion())
+	}
+	return item, nil
+}
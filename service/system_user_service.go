@@ -1,54 +1,95 @@
 package service
 
 import (
+	"context"
 	"errors"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
+	"github.com/roksky/bootstrap-api/apperror"
 	"github.com/roksky/bootstrap-api/model"
 	"github.com/roksky/bootstrap-api/repository"
 )
 
+// SystemUserService manages the system users, through the generic CrudService, and registers
+// their organizations.
 type SystemUserService struct {
-	systemUserRepo                   *repository.SystemUserRepo
+	*CrudService[model.SystemUser, uuid.UUID, repository.SystemUserSearch]
+	systemUserRepo                   repository.BaseRepository[model.SystemUser, uuid.UUID, repository.SystemUserSearch]
 	organizationRepo                 repository.BaseRepository[model.Organization, uuid.UUID, repository.OrganizationSearch]
 	systemUserOrganizationRepository repository.BaseRepository[model.SystemUserOrganization, uuid.UUID, repository.SystemUserOrganizationSearch]
 	Validate                         *validator.Validate
 }
 
+// NewSystemUserService creates a SystemUserService on the deprecated system user repository.
+//
+// Deprecated: use NewSystemUserCrudService.
 func NewSystemUserService(repository *repository.SystemUserRepo, organizationRepo repository.BaseRepository[model.Organization, uuid.UUID, repository.OrganizationSearch], systemUserOrganizationRepository repository.BaseRepository[model.SystemUserOrganization, uuid.UUID, repository.SystemUserOrganizationSearch], validate *validator.Validate) *SystemUserService {
+	return NewSystemUserCrudService(repository.Repository(), organizationRepo, systemUserOrganizationRepository, validate)
+}
+
+// NewSystemUserCrudService creates a SystemUserService.
+func NewSystemUserCrudService(repository repository.BaseRepository[model.SystemUser, uuid.UUID, repository.SystemUserSearch], organizationRepo repository.BaseRepository[model.Organization, uuid.UUID, repository.OrganizationSearch], systemUserOrganizationRepository repository.BaseRepository[model.SystemUserOrganization, uuid.UUID, repository.SystemUserOrganizationSearch], validate *validator.Validate) *SystemUserService {
+	crudService := NewCrudService(repository, validate)
 	return &SystemUserService{
+		CrudService:                      crudService,
 		systemUserRepo:                   repository,
 		organizationRepo:                 organizationRepo,
 		systemUserOrganizationRepository: systemUserOrganizationRepository,
-		Validate:                         validate,
+		Validate:                         crudService.Validate,
 	}
 }
 
-// SearchUserByEmailOrMobile finds a user by email of mobile
+// SearchUserByEmailOrMobile finds a user by email of mobile, an empty user when there is none.
 func (e *SystemUserService) SearchUserByEmailOrMobile(email string, mobileNumber string) (*model.SystemUser, error) {
 	if email != "" {
-		return e.systemUserRepo.FindByUserName(email)
+		return orEmptyUser(e.FindByUserName(email))
 	}
 	if mobileNumber != "" {
-		return e.systemUserRepo.FindByUserName(email)
+		return orEmptyUser(e.FindByUserName(mobileNumber))
 	}
-	return nil, errors.New("email and mobile are both nil")
+	return nil, apperror.Validation("user_name_missing", "email and mobile are both nil")
 }
 
-// RegisterOrganization creates an organization
+// RegisterOrganization creates an organization. It is not subject to the tenant of the service
+// context, if any, see repository.WithoutTenantScope.
 func (e *SystemUserService) RegisterOrganization(org *model.Organization) (*model.Organization, error) {
-	organization, err := e.organizationRepo.Save(nil, nil, org)
+	organization, err := repository.Bind(e.organizationRepo, e.unscoped(nil)).Save(nil, nil, org)
 	if err != nil {
 		return nil, err
 	}
 	return organization, nil
 }
 
-func (e *SystemUserService) DeleteUserById(userId uuid.UUID) error {
-	return e.systemUserRepo.Delete(&model.SystemUser{
-		UserId: userId,
+// RegisterOrganizationForUser creates an organization with the user as its owner.
+// Both are written in one transaction, joining the one carried by ctx if any, and are not subject
+// to the tenant of ctx: the user may not be a member of any organization yet.
+func (e *SystemUserService) RegisterOrganizationForUser(ctx context.Context, org *model.Organization, userId uuid.UUID) (*model.Organization, error) {
+	err := repository.WithTransaction(e.unscoped(ctx), e.organizationRepo.GetDB(), func(ctx context.Context) error {
+		tx := repository.TxFromContext(ctx)
+		_, err := e.organizationRepo.Save(tx, nil, org)
+		if err != nil {
+			return err
+		}
+
+		membership := &model.SystemUserOrganization{
+			SystemUserId:   userId,
+			OrganizationId: org.Id,
+			UserRole:       model.Owner,
+		}
+		membership.CreatedBy = org.CreatedBy
+		membership.UpdatedBy = org.UpdatedBy
+		_, err = e.systemUserOrganizationRepository.Save(tx, nil, membership)
+		return err
 	})
+	if err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+func (e *SystemUserService) DeleteUserById(userId uuid.UUID) error {
+	return e.systemUserRepo.Delete(nil, nil, userId)
 }
 
 func (e *SystemUserService) DeleteOrgById(orgId uuid.UUID) error {
@@ -67,16 +108,64 @@ func (e *SystemUserService) DeleteOrgSystemUserById(orgId uuid.UUID, userId uuid
 	}
 
 	if len(users) == 0 {
-		return errors.New("user not found")
+		return repository.ErrNotFound
 	}
 
 	return e.systemUserOrganizationRepository.Delete(nil, nil, users[0].Id)
 }
 
+// GetUserByUserName returns an empty user when no user has the given name.
+//
+// Deprecated: use FindByUserName.
 func (e *SystemUserService) GetUserByUserName(userName string) (*model.SystemUser, error) {
-	return e.systemUserRepo.FindByUserName(userName)
+	return orEmptyUser(e.FindByUserName(userName))
 }
 
+// GetByUserId returns an empty user when no user has the given id.
+//
+// Deprecated: use FindByUserId.
 func (e *SystemUserService) GetByUserId(userId uuid.UUID) (*model.SystemUser, error) {
-	return e.systemUserRepo.FindById(userId)
+	return orEmptyUser(e.FindByUserId(userId))
+}
+
+// FindByUserId returns the user with the given id, repository.ErrNotFound when there is none.
+func (e *SystemUserService) FindByUserId(userId uuid.UUID) (*model.SystemUser, error) {
+	return e.systemUserRepo.FindById(nil, nil, userId)
+}
+
+// FindByUserName returns the user with the given user name, repository.ErrNotFound when there is none.
+func (e *SystemUserService) FindByUserName(userName string) (*model.SystemUser, error) {
+	if userName == "" {
+		return nil, repository.ErrNotFound
+	}
+	search := &repository.SystemUserSearch{UserName: userName}
+	search.PageSize = 1
+	users, err := e.systemUserRepo.Search(nil, search)
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	return users[0], nil
+}
+
+// orEmptyUser returns an empty user in place of repository.ErrNotFound, as the former repository did.
+func orEmptyUser(user *model.SystemUser, err error) (*model.SystemUser, error) {
+	if errors.Is(err, repository.ErrNotFound) {
+		return &model.SystemUser{}, nil
+	}
+	return user, err
+}
+
+// unscoped returns ctx, the context of the service when ctx is nil, giving access to the models of
+// every organization.
+func (e *SystemUserService) unscoped(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = e.ctx
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return repository.WithoutTenantScope(ctx)
 }
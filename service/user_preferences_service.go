@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/roksky/bootstrap-api/apperror"
+	"github.com/roksky/bootstrap-api/helper"
+	"github.com/roksky/bootstrap-api/model"
+	"github.com/roksky/bootstrap-api/repository"
+	"github.com/roksky/bootstrap-api/types"
+)
+
+// ErrPreferencesConflict is returned when the preferences of a user cannot be saved, concurrent
+// saves conflicting with each other.
+var ErrPreferencesConflict = apperror.Conflict("preferences_conflict", "preferences were saved concurrently")
+
+// UserPreferencesService keeps one preferences document per user. The document returned to a user
+// is the one registered with SetDefaults, merge patched with the preferences the user saved.
+type UserPreferencesService struct {
+	repository repository.BaseRepository[model.UserPreferences, uuid.UUID, repository.UserPreferencesSearch]
+	defaults   map[string]interface{}
+	ctx        context.Context
+}
+
+func NewUserPreferencesService(repository repository.BaseRepository[model.UserPreferences, uuid.UUID, repository.UserPreferencesSearch]) *UserPreferencesService {
+	return &UserPreferencesService{
+		repository: repository,
+		defaults:   map[string]interface{}{},
+	}
+}
+
+// SetDefaults registers the preferences of users who did not set them.
+func (e *UserPreferencesService) SetDefaults(defaults map[string]interface{}) *UserPreferencesService {
+	e.defaults = defaults
+	return e
+}
+
+// WithContext returns a copy of the service bound to ctx.
+func (e *UserPreferencesService) WithContext(ctx context.Context) *UserPreferencesService {
+	bound := *e
+	bound.repository = repository.Bind(e.repository, ctx)
+	bound.ctx = ctx
+	return &bound
+}
+
+// Get returns the preferences of a user, defaults included.
+func (e *UserPreferencesService) Get(userId uuid.UUID) (map[string]interface{}, error) {
+	preferences, err := e.find(userId)
+	if err != nil {
+		return nil, err
+	}
+	if preferences == nil {
+		return e.withDefaults(nil), nil
+	}
+	return e.withDefaults(preferences.Preferences), nil
+}
+
+// Replace saves document as the preferences of a user, replacing the saved ones.
+func (e *UserPreferencesService) Replace(userId uuid.UUID, document map[string]interface{}) (map[string]interface{}, error) {
+	return e.save(userId, func(map[string]interface{}) map[string]interface{} {
+		return document
+	})
+}
+
+// Patch applies a JSON merge patch to the saved preferences of a user.
+func (e *UserPreferencesService) Patch(userId uuid.UUID, patch map[string]interface{}) (map[string]interface{}, error) {
+	return e.save(userId, func(saved map[string]interface{}) map[string]interface{} {
+		return helper.MergePatch(saved, patch).(map[string]interface{})
+	})
+}
+
+func (e *UserPreferencesService) save(userId uuid.UUID, change func(saved map[string]interface{}) map[string]interface{}) (map[string]interface{}, error) {
+	if userId == uuid.Nil {
+		return nil, apperror.Validation("user_id_missing", "user id is missing")
+	}
+	preferences, err := e.write(userId, change)
+	// a concurrent first save of the user won the insert, the change then applies on top of it
+	if repository.IsUniqueViolation(err) {
+		preferences, err = e.write(userId, change)
+	}
+	if repository.IsUniqueViolation(err) {
+		return nil, ErrPreferencesConflict
+	}
+	if err != nil {
+		return nil, err
+	}
+	return e.withDefaults(preferences.Preferences), nil
+}
+
+// write inserts the preferences of a user, or updates the saved ones. The insert runs in a
+// transaction of its own, or a savepoint of the one of the service context, which can go on when
+// the insert fails.
+func (e *UserPreferencesService) write(userId uuid.UUID, change func(saved map[string]interface{}) map[string]interface{}) (*model.UserPreferences, error) {
+	preferences, err := e.find(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	actor := repository.ActorFromContext(e.ctx)
+	if preferences != nil {
+		preferences.Preferences = change(preferences.Preferences)
+		preferences.SetUpdatedBy(actor)
+		return e.repository.Update(nil, nil, preferences)
+	}
+
+	preferences = &model.UserPreferences{SystemUserId: userId}
+	preferences.Preferences = change(map[string]interface{}{})
+	preferences.SetCreatedBy(actor)
+	preferences.SetUpdatedBy(actor)
+	err = repository.WithTransaction(e.ctx, e.repository.GetDB(), func(ctx context.Context) error {
+		var err error
+		preferences, err = repository.Bind(e.repository, ctx).Save(nil, nil, preferences)
+		return err
+	})
+	return preferences, err
+}
+
+// find returns the saved preferences of a user, nil when there are none.
+func (e *UserPreferencesService) find(userId uuid.UUID) (*model.UserPreferences, error) {
+	search := &repository.UserPreferencesSearch{SystemUserId: userId}
+	search.PageSize = 1
+	found, err := e.repository.Search(nil, search)
+	if err != nil || len(found) == 0 {
+		return nil, err
+	}
+	return found[0], nil
+}
+
+func (e *UserPreferencesService) withDefaults(saved types.JSONMap) map[string]interface{} {
+	return helper.MergePatch(e.defaults, map[string]interface{}(saved)).(map[string]interface{})
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/roksky/bootstrap-api/database"
 	"github.com/roksky/bootstrap-api/helper"
 	"github.com/roksky/bootstrap-api/job"
+	"github.com/roksky/bootstrap-api/repository"
 	"github.com/roksky/bootstrap-api/router"
 
 	"github.com/rs/zerolog"
@@ -20,6 +21,9 @@ import (
 type StratUpConfig struct {
 	IntrospectURL string `json:"introspect_url"`
 	SentryDSN     string `json:"sentry_dsn"`
+	// EnableTenantScoping restricts the tenant-owned models to the organization of the token user,
+	// see router.Router.EnableTenantScoping.
+	EnableTenantScoping bool `json:"enable_tenant_scoping"`
 }
 
 // IntrospectURL := "https://auth.example.com/oauth/introspect"
@@ -46,6 +50,9 @@ func StartApp(startupConfig StratUpConfig, provider controller.Provider) {
 	err = myDb.AutoMigrate()
 	helper.ErrorPanic(err)
 
+	err = repository.EnableAuditLog(db)
+	helper.ErrorPanic(err)
+
 	// Router
 	routeHandler, err := router.NewRouteHandler("/api")
 	helper.ErrorPanic(err)
@@ -59,6 +66,13 @@ func StartApp(startupConfig StratUpConfig, provider controller.Provider) {
 	err = routeHandler.EnableAuth(startupConfig.IntrospectURL, config.EnvConfigs.Auth.ClientId, config.EnvConfigs.Auth.ClientSecret)
 	helper.ErrorPanic(err)
 
+	routeHandler.RejectInactiveUsers(repository.NewSystemUserRepository(db))
+
+	if startupConfig.EnableTenantScoping {
+		err = routeHandler.EnableTenantScoping(repository.NewSystemUserOrganizationRepository(db))
+		helper.ErrorPanic(err)
+	}
+
 	routeHandler.RegisterRoutes(provider.GetControllers())
 	initJobExecutor(provider.GetJobs())
 
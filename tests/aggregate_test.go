@@ -0,0 +1,56 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/roksky/bootstrap-api/data/response"
+	"github.com/roksky/bootstrap-api/model"
+	"github.com/roksky/bootstrap-api/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregateGroupsMatchingEntities(t *testing.T) {
+	db, recorder := dryRunDB(t)
+
+	search := &repository.SystemUserOrganizationSearch{UserRole: model.Owner}
+	_, err := repository.NewSystemUserOrganizationRepository(db).(*repository.SystemUserOrganizationRepository).Aggregate(nil, search, &repository.AggregateRequest{
+		GroupBy: []string{"userRole", "dateCreated:month"},
+		Metrics: []string{"count", "max:dateUpdated"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, `SELECT "user_role" AS "userRole", date_trunc('month', "date_created") AS "dateCreated", `+
+		`count(*) AS "count", max("date_updated") AS "maxDateUpdated" FROM "system_user_organizations" `+
+		`WHERE "system_user_organizations"."user_role" = 'owner' AND "system_user_organizations"."date_deleted" IS NULL `+
+		`GROUP BY 1, 2 ORDER BY 1, 2`, recorder.last())
+}
+
+func TestAggregateRejectsFieldsOutsideTheSortRegistry(t *testing.T) {
+	db, recorder := dryRunDB(t)
+
+	_, err := repository.NewOrganizationRepository(db).(*repository.OrganizationRepository).Aggregate(nil, nil, &repository.AggregateRequest{
+		GroupBy: []string{"createdBy"},
+	})
+
+	var aggregateError *repository.InvalidAggregateError
+	if assert.ErrorAs(t, err, &aggregateError) {
+		assert.Equal(t, []string{"dateCreated", "dateUpdated", "name"}, aggregateError.Allowed)
+	}
+	assert.Empty(t, recorder.statements)
+}
+
+func TestStatsRouteRejectsUnknownMetrics(t *testing.T) {
+	router := organizationRouter(t)
+
+	req, _ := http.NewRequest(http.MethodGet, "/org/stats?groupBy=name&metric=median:name", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	var problem response.Problem
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &problem))
+	assert.Equal(t, `invalid aggregate "median:name": unknown function, allowed values are count, sum, avg, min, max`, problem.Detail)
+}
@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/roksky/bootstrap-api/constants"
+	"github.com/roksky/bootstrap-api/controller"
+	"github.com/roksky/bootstrap-api/model"
+	"github.com/roksky/bootstrap-api/repository"
+	"github.com/roksky/bootstrap-api/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAuditLogKeepsChangedFields(t *testing.T) {
+	ctx := repository.WithRequestId(repository.WithActor(context.Background(), "alice"), "request-1")
+	before := &model.Organization{Name: "acme"}
+	before.Id = uuid.New()
+	after := *before
+	after.Name = "acme inc"
+	after.Version = 2
+
+	db, _ := dryRunDB(t)
+	entry, err := repository.NewAuditLog(db.WithContext(ctx), model.AuditUpdate, before.Id.String(), before, &after)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", entry.Actor)
+	assert.Equal(t, "request-1", entry.RequestId)
+	assert.Equal(t, "Organization", entry.EntityType)
+	assert.Equal(t, before.Id, *entry.OrganizationId)
+
+	var changed map[string]any
+	assert.NoError(t, json.Unmarshal(entry.After, &changed))
+	assert.Equal(t, map[string]any{"name": "acme inc", "version": float64(2)}, changed)
+}
+
+func TestNewAuditLogOfCreateHasNoBefore(t *testing.T) {
+	after := &model.Organization{Name: "acme"}
+
+	db, _ := dryRunDB(t)
+	entry, err := repository.NewAuditLog(db, model.AuditCreate, "1", nil, after)
+
+	assert.NoError(t, err)
+	assert.Nil(t, entry.Before)
+	assert.Contains(t, string(entry.After), `"name":"acme"`)
+}
+
+func TestWriteIsRolledBackWhenItCannotBeAudited(t *testing.T) {
+	db, conn := fakeDB(t, 1)
+	assert.NoError(t, repository.EnableAuditLog(db))
+	conn.failing = `SELECT * FROM "organizations"`
+
+	_, err := repository.NewOrganizationRepository(db).Save(nil, nil, &model.Organization{Name: "acme"})
+
+	assert.Error(t, err)
+	assert.Equal(t, "BEGIN", conn.events[0])
+	assert.NotEmpty(t, conn.statement(`INSERT INTO "organizations"`))
+	assert.Equal(t, "ROLLBACK", conn.events[len(conn.events)-1])
+	assert.NotContains(t, conn.events, "COMMIT")
+}
+
+func TestAuditLogRequiresAdminScope(t *testing.T) {
+	db, _ := dryRunDB(t)
+	auditLogService := service.NewAuditLogService(repository.NewAuditLogRepository(db), validator.New())
+
+	router := controllerRouter(controller.NewAuditLogController(auditLogService), withToken("read"))
+	assert.Equal(t, http.StatusForbidden, serve(router, http.MethodGet, "/audit-log").Code)
+	assert.Equal(t, http.StatusForbidden, serve(router, http.MethodGet, "/audit-log/"+uuid.NewString()).Code)
+
+	router = controllerRouter(controller.NewAuditLogController(auditLogService), withToken(constants.AdminScope))
+	assert.Equal(t, http.StatusOK, serve(router, http.MethodGet, "/audit-log").Code)
+}
+
+func TestAuditLogIsReadOnly(t *testing.T) {
+	db, _ := dryRunDB(t)
+	auditLogService := service.NewAuditLogService(repository.NewAuditLogRepository(db), validator.New())
+	router := controllerRouter(controller.NewAuditLogController(auditLogService), withToken(constants.AdminScope))
+
+	entryPath := "/audit-log/" + uuid.NewString()
+	for _, method := range []string{http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		assert.Equal(t, http.StatusNotFound, serve(router, method, entryPath).Code, method)
+	}
+	assert.Equal(t, http.StatusNotFound, serve(router, http.MethodPost, "/audit-log").Code)
+	assert.Equal(t, http.StatusNotFound, serve(router, http.MethodPost, entryPath+"/restore").Code)
+	assert.Equal(t, http.StatusOK, serve(router, http.MethodGet, "/audit-log/stats").Code)
+}
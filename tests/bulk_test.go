@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/roksky/bootstrap-api/controller"
+	"github.com/roksky/bootstrap-api/data/response"
+	"github.com/roksky/bootstrap-api/model"
+	"github.com/roksky/bootstrap-api/repository"
+	"github.com/roksky/bootstrap-api/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func organizationRouter(t *testing.T) *gin.Engine {
+	db, _ := dryRunDB(t)
+	return controllerRouter(controller.NewOrganizationController(
+		service.NewOrganizationService(repository.NewOrganizationRepository(db), validator.New())))
+}
+
+// controllerRouter registers the routes of a controller on a gin engine, behind the given middleware.
+func controllerRouter(registered controller.Controller, middleware ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	group := router.Group(registered.GroupName(), middleware...)
+	for _, route := range registered.Handlers() {
+		switch route.GetHttpMethod() {
+		case controller.GET:
+			group.GET(route.GetUrlTemplate(), route.GetHandlerFunc())
+		case controller.POST:
+			group.POST(route.GetUrlTemplate(), route.GetHandlerFunc())
+		case controller.PUT:
+			group.PUT(route.GetUrlTemplate(), route.GetHandlerFunc())
+		case controller.PATCH:
+			group.PATCH(route.GetUrlTemplate(), route.GetHandlerFunc())
+		case controller.DELETE:
+			group.DELETE(route.GetUrlTemplate(), route.GetHandlerFunc())
+		}
+	}
+	return router
+}
+
+func TestPartialBulkDeleteReportsEachItem(t *testing.T) {
+	router := organizationRouter(t)
+
+	req, _ := http.NewRequest(http.MethodDelete, "/org/s?mode=partial", strings.NewReader(`["not-an-id"]`))
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusMultiStatus, resp.Code)
+	var results []response.BulkItemResult[*model.Organization]
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &results))
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, 0, results[0].Index)
+		assert.Equal(t, http.StatusBadRequest, results[0].Status)
+		assert.Equal(t, "not-an-id", results[0].Id)
+		assert.NotNil(t, results[0].Error)
+	}
+}
+
+func TestBulkRejectsUnknownMode(t *testing.T) {
+	router := organizationRouter(t)
+
+	req, _ := http.NewRequest(http.MethodDelete, "/org/s?mode=best-effort", strings.NewReader(`[]`))
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
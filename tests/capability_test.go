@@ -0,0 +1,45 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/roksky/bootstrap-api/controller"
+	"github.com/roksky/bootstrap-api/data/response"
+	"github.com/roksky/bootstrap-api/model"
+	"github.com/roksky/bootstrap-api/repository"
+	"github.com/roksky/bootstrap-api/service"
+	"github.com/stretchr/testify/assert"
+)
+
+// baseOrganizationService implements the methods of service.BaseService only, like the services
+// written before the optional interfaces.
+type baseOrganizationService struct {
+	service.BaseService[model.Organization, uuid.UUID, repository.OrganizationSearch]
+}
+
+func TestControllersServeServicesWithoutOptionalOperations(t *testing.T) {
+	db, recorder := dryRunDB(t)
+	organizationService := &baseOrganizationService{
+		BaseService: service.NewOrganizationService(repository.NewOrganizationRepository(db), validator.New()),
+	}
+	router := controllerRouter(controller.NewOrganizationController(organizationService), withToken(""))
+
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/org", nil)
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, recorder.statements[0], `SELECT * FROM "organizations"`)
+
+	resp = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/org/changes", nil)
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	var problem response.Problem
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &problem))
+	assert.Equal(t, "operation_unsupported", problem.Code)
+}
@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/roksky/bootstrap-api/model"
+	"github.com/roksky/bootstrap-api/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangesSinceTimestamp(t *testing.T) {
+	db, recorder := dryRunDB(t)
+	repo := repository.NewOrganizationRepository(db).(*repository.OrganizationRepository)
+
+	search := &repository.OrganizationSearch{}
+	search.PageSize = 50
+	changes, err := repo.Changes(nil, search, "2024-05-01T10:00:00Z")
+
+	assert.NoError(t, err)
+	assert.False(t, changes.HasMore)
+	assert.Equal(t, "2024-05-01T10:00:00Z", changes.SyncToken)
+	sql := recorder.statements[0]
+	assert.Contains(t, sql, `WHERE COALESCE("organizations"."date_deleted", "organizations"."date_updated") > '2024-05-01 10:00:00'`)
+	assert.Contains(t, sql, `ORDER BY COALESCE("organizations"."date_deleted", "organizations"."date_updated"), "organizations"."id" LIMIT 51`)
+	assert.NotContains(t, sql, `"date_deleted" IS NULL`)
+}
+
+func TestChangesRejectsInvalidSince(t *testing.T) {
+	db, _ := dryRunDB(t)
+	repo := repository.NewOrganizationRepository(db).(*repository.OrganizationRepository)
+
+	_, err := repo.Changes(nil, &repository.OrganizationSearch{}, "yesterday")
+
+	assert.ErrorIs(t, err, repository.ErrInvalidSyncToken)
+}
+
+func TestPurgeKeepsTombstone(t *testing.T) {
+	db, conn := fakeDB(t, 1)
+	organizationId := uuid.New()
+	conn.results = map[string]fakeRows{
+		`SELECT * FROM "organizations"`: {columns: []string{"id", "name"}, values: [][]driver.Value{{organizationId.String(), "Acme"}}},
+	}
+
+	err := repository.NewOrganizationRepository(db).(*repository.OrganizationRepository).
+		WithContext(repository.WithActor(context.Background(), "admin")).(repository.RestoreRepository[model.Organization, uuid.UUID, repository.OrganizationSearch]).
+		Purge(nil, nil, []uuid.UUID{organizationId})
+
+	assert.NoError(t, err)
+	insert := conn.statement(`INSERT INTO "purged_entities"`)
+	assert.Contains(t, insert, `("entity_type","entity_id","organization","date_deleted","deleted_by")`)
+	assert.Contains(t, insert, `ON CONFLICT`)
+	assert.Equal(t, "COMMIT", conn.events[len(conn.events)-1])
+}
+
+func TestChangesReportPurgedEntities(t *testing.T) {
+	db, conn := fakeDB(t, 1)
+	updated, purged := uuid.New(), uuid.New()
+	updatedAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	conn.results = map[string]fakeRows{
+		`SELECT * FROM "organizations"`: {columns: []string{"id", "name", "date_updated"},
+			values: [][]driver.Value{{updated.String(), "Acme", updatedAt}}},
+		`SELECT * FROM "purged_entities"`: {columns: []string{"entity_type", "entity_id", "date_deleted", "deleted_by"},
+			values: [][]driver.Value{{"Organization", purged.String(), updatedAt.Add(time.Hour), "admin"}}},
+	}
+
+	changes, err := repository.NewOrganizationRepository(db).(*repository.OrganizationRepository).Changes(nil, &repository.OrganizationSearch{}, "2024-05-01T00:00:00Z")
+
+	assert.NoError(t, err)
+	if assert.Len(t, changes.Upserted, 1) {
+		assert.Equal(t, updated, changes.Upserted[0].Id)
+	}
+	assert.Equal(t, []model.Tombstone{{Id: purged.String(), DateDeleted: updatedAt.Add(time.Hour), DeletedBy: "admin"}}, changes.Deleted)
+	assert.Contains(t, conn.statement(`SELECT * FROM "purged_entities"`), `"entity_type" = $1`)
+
+	// the sync token resumes after the purge
+	token := changes.SyncToken
+	conn.results = nil
+	changes, err = repository.NewOrganizationRepository(db).(*repository.OrganizationRepository).Changes(nil, &repository.OrganizationSearch{}, token)
+	assert.NoError(t, err)
+	assert.Empty(t, changes.Deleted)
+	assert.Equal(t, token, changes.SyncToken)
+}
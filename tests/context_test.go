@@ -0,0 +1,32 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/roksky/bootstrap-api/repository"
+	"github.com/roksky/bootstrap-api/service"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type contextKey struct{}
+
+func TestBoundServiceQueriesWithContext(t *testing.T) {
+	db, _ := dryRunDB(t)
+	var queried context.Context
+	err := db.Callback().Query().Before("gorm:query").Register("test:context", func(tx *gorm.DB) {
+		queried = tx.Statement.Context
+	})
+	assert.NoError(t, err)
+
+	organizationService := service.NewOrganizationService(repository.NewOrganizationRepository(db), validator.New())
+	ctx := context.WithValue(context.Background(), contextKey{}, "request")
+	_, _ = service.Bind(organizationService, ctx).FindById(nil, uuid.New())
+
+	if assert.NotNil(t, queried) {
+		assert.Equal(t, "request", queried.Value(contextKey{}))
+	}
+}
@@ -0,0 +1,51 @@
+package tests
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/roksky/bootstrap-api/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchByCursorFirstPage(t *testing.T) {
+	db, recorder := dryRunDB(t)
+	repo := repository.NewOrganizationRepository(db).(*repository.OrganizationRepository)
+
+	search := &repository.OrganizationSearch{}
+	search.PageSize = 20
+	search.OrderBy = "name:desc"
+	items, nextCursor, err := repo.SearchByCursor(nil, search)
+
+	assert.NoError(t, err)
+	assert.Empty(t, items)
+	assert.Empty(t, nextCursor)
+	assert.Contains(t, recorder.last(), `ORDER BY "organizations"."name" DESC,"organizations"."id" LIMIT 21`)
+}
+
+func TestSearchByCursorNextPage(t *testing.T) {
+	db, recorder := dryRunDB(t)
+	repo := repository.NewOrganizationRepository(db).(*repository.OrganizationRepository)
+	lastId := uuid.New()
+
+	search := &repository.OrganizationSearch{}
+	search.PageSize = 20
+	search.OrderBy = "name:desc"
+	search.Cursor = base64.RawURLEncoding.EncodeToString([]byte(`{"v":["acme","` + lastId.String() + `"]}`))
+	_, _, err := repo.SearchByCursor(nil, search)
+
+	assert.NoError(t, err)
+	assert.Contains(t, recorder.last(), `("organizations"."name" < 'acme' OR ("organizations"."name" = 'acme' AND "organizations"."id" > '`+lastId.String()+`'))`)
+}
+
+func TestSearchByCursorRejectsInvalidCursor(t *testing.T) {
+	db, _ := dryRunDB(t)
+	repo := repository.NewOrganizationRepository(db).(*repository.OrganizationRepository)
+
+	search := &repository.OrganizationSearch{}
+	search.Cursor = "not a cursor"
+	_, _, err := repo.SearchByCursor(nil, search)
+
+	assert.ErrorIs(t, err, repository.ErrInvalidCursor)
+}
@@ -0,0 +1,202 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// sqlRecorder is a gorm logger keeping the SQL of every statement.
+type sqlRecorder struct {
+	logger.Interface
+	statements []string
+}
+
+func (r *sqlRecorder) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	sql, _ := fc()
+	r.statements = append(r.statements, sql)
+}
+
+func (r *sqlRecorder) last() string {
+	if len(r.statements) == 0 {
+		return ""
+	}
+	return r.statements[len(r.statements)-1]
+}
+
+// fakeConn is a database connection without a database: every statement it executes affects
+// rowsAffected rows, the queries return the rows of the first prefix of results they start with,
+// no rows otherwise, and the statements and the transaction boundaries are recorded in events. The
+// statements starting with failing, when set, fail with failure, errFakeStatement when nil.
+type fakeConn struct {
+	rowsAffected int64
+	failing      string
+	failure      error
+	results      map[string]fakeRows
+	events       []string
+}
+
+// fakeRows are the rows returned by a fakeConn query, once skip queries returned no rows.
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+	skip    int
+}
+
+func (c *fakeConn) Connect(context.Context) (driver.Conn, error) {
+	return c, nil
+}
+
+func (c *fakeConn) Driver() driver.Driver {
+	return c
+}
+
+func (c *fakeConn) Open(string) (driver.Conn, error) {
+	return c, nil
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.events = append(c.events, "BEGIN")
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error {
+	c.events = append(c.events, "COMMIT")
+	return nil
+}
+
+func (c *fakeConn) Rollback() error {
+	c.events = append(c.events, "ROLLBACK")
+	return nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.events = append(c.events, query)
+	if c.fails(query) {
+		return nil, c.failureOf()
+	}
+	return driver.RowsAffected(c.rowsAffected), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.events = append(c.events, query)
+	if c.fails(query) {
+		return nil, c.failureOf()
+	}
+	for prefix, rows := range c.results {
+		if !strings.HasPrefix(query, prefix) {
+			continue
+		}
+		if rows.skip > 0 {
+			rows.skip--
+			c.results[prefix] = rows
+			return noRows{}, nil
+		}
+		return &rowsIterator{fakeRows: rows}, nil
+	}
+	return noRows{}, nil
+}
+
+func (c *fakeConn) fails(query string) bool {
+	return c.failing != "" && strings.HasPrefix(query, c.failing)
+}
+
+func (c *fakeConn) failureOf() error {
+	if c.failure != nil {
+		return c.failure
+	}
+	return errFakeStatement
+}
+
+// statement returns the first statement executed starting with prefix, empty when there is none.
+func (c *fakeConn) statement(prefix string) string {
+	for _, event := range c.events {
+		if strings.HasPrefix(event, prefix) {
+			return event
+		}
+	}
+	return ""
+}
+
+// CheckNamedValue passes the arguments of the statements as they are, none of them being sent.
+func (c *fakeConn) CheckNamedValue(*driver.NamedValue) error {
+	return nil
+}
+
+var errFakeStatement = errors.New("statement failed")
+
+type noRows struct{}
+
+func (noRows) Columns() []string {
+	return nil
+}
+
+func (noRows) Close() error {
+	return nil
+}
+
+func (noRows) Next([]driver.Value) error {
+	return io.EOF
+}
+
+type rowsIterator struct {
+	fakeRows
+	next int
+}
+
+func (r *rowsIterator) Columns() []string {
+	return r.columns
+}
+
+func (r *rowsIterator) Close() error {
+	return nil
+}
+
+func (r *rowsIterator) Next(dest []driver.Value) error {
+	if r.next == len(r.values) {
+		return io.EOF
+	}
+	copy(dest, r.values[r.next])
+	r.next++
+	return nil
+}
+
+func openDB(t *testing.T, conn *fakeConn, config *gorm.Config) *gorm.DB {
+	config.SkipDefaultTransaction = true
+	config.DisableAutomaticPing = true
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(conn)}), config)
+	if err != nil {
+		t.Fatalf("Couldn't open fake database: %v\n", err)
+	}
+	return db
+}
+
+// dryRunDB returns a postgres gorm.DB that only renders SQL and never connects.
+func dryRunDB(t *testing.T) (*gorm.DB, *sqlRecorder) {
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	return openDB(t, &fakeConn{}, &gorm.Config{DryRun: true, Logger: recorder}), recorder
+}
+
+// fakeDB returns a postgres gorm.DB executing its statements on a fakeConn, whose statements affect
+// rowsAffected rows.
+func fakeDB(t *testing.T, rowsAffected int64) (*gorm.DB, *fakeConn) {
+	conn := &fakeConn{rowsAffected: rowsAffected}
+	return openDB(t, conn, &gorm.Config{Logger: logger.Discard}), conn
+}
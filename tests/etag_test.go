@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/roksky/bootstrap-api/controller"
+	"github.com/roksky/bootstrap-api/model"
+	"github.com/roksky/bootstrap-api/repository"
+	"github.com/roksky/bootstrap-api/service"
+	"github.com/stretchr/testify/assert"
+)
+
+// storedOrganizationService finds the same organization whatever the id, the fake database
+// returning no rows.
+type storedOrganizationService struct {
+	*service.CrudService[model.Organization, uuid.UUID, repository.OrganizationSearch]
+	organization *model.Organization
+}
+
+func (s *storedOrganizationService) WithContext(ctx context.Context) service.BaseService[model.Organization, uuid.UUID, repository.OrganizationSearch] {
+	bound := *s
+	bound.CrudService = s.CrudService.WithContext(ctx).(*service.CrudService[model.Organization, uuid.UUID, repository.OrganizationSearch])
+	return &bound
+}
+
+func (s *storedOrganizationService) FindById(*repository.OrganizationSearch, uuid.UUID) (*model.Organization, error) {
+	stored := *s.organization
+	return &stored, nil
+}
+
+// storedOrganizationRouter serves an organization at the given version, on a database whose
+// writes affect rowsAffected rows.
+func storedOrganizationRouter(t *testing.T, version int64, rowsAffected int64) (*gin.Engine, string, *fakeConn) {
+	db, conn := fakeDB(t, rowsAffected)
+	organization := &model.Organization{IdentifiedModel: model.IdentifiedModel{Id: uuid.New(), Version: version}, Name: "Acme"}
+	organizationService := &storedOrganizationService{
+		CrudService:  service.NewCrudService[model.Organization, uuid.UUID](repository.NewOrganizationRepository(db), validator.New()),
+		organization: organization,
+	}
+	return controllerRouter(controller.NewOrganizationController(organizationService), withToken("")), "/org/" + organization.Id.String(), conn
+}
+
+func conditionalRequest(router *gin.Engine, method string, path string, header string, value string, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(header, value)
+	if method == http.MethodPatch {
+		req.Header.Set("Content-Type", controller.MergePatchContentType)
+	}
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestFindByIdHonorsIfNoneMatch(t *testing.T) {
+	router, path, _ := storedOrganizationRouter(t, 3, 1)
+
+	resp := conditionalRequest(router, http.MethodGet, path, "If-None-Match", `"v2"`, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, `"v3"`, resp.Header().Get("ETag"))
+
+	for _, header := range []string{`"v3"`, `W/"v3"`, `"v1", "v3"`, "*"} {
+		resp = conditionalRequest(router, http.MethodGet, path, "If-None-Match", header, "")
+		assert.Equal(t, http.StatusNotModified, resp.Code, header)
+		assert.Empty(t, resp.Body.String())
+		assert.Equal(t, `"v3"`, resp.Header().Get("ETag"))
+	}
+}
+
+func TestWritesHonorIfMatch(t *testing.T) {
+	router, path, _ := storedOrganizationRouter(t, 3, 1)
+
+	resp := conditionalRequest(router, http.MethodDelete, path, "If-Match", `"v2"`, "")
+	assert.Equal(t, http.StatusPreconditionFailed, resp.Code)
+	resp = conditionalRequest(router, http.MethodDelete, path, "If-Match", `W/"v3"`, "")
+	assert.Equal(t, http.StatusPreconditionFailed, resp.Code, "weak tags do not match")
+	resp = conditionalRequest(router, http.MethodDelete, path, "If-Match", `"v3"`, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = conditionalRequest(router, http.MethodPatch, path, "If-Match", `"v2"`, `{"name": "Other"}`)
+	assert.Equal(t, http.StatusPreconditionFailed, resp.Code)
+}
+
+func TestIfMatchIsCheckedByTheWrite(t *testing.T) {
+	// the organization is modified between its read and its deletion
+	router, path, conn := storedOrganizationRouter(t, 3, 0)
+
+	resp := conditionalRequest(router, http.MethodDelete, path, "If-Match", `"v3"`, "")
+
+	assert.Equal(t, http.StatusPreconditionFailed, resp.Code)
+	assert.Contains(t, conn.statement(`UPDATE "organizations" SET`), `"organizations"."version" = $`)
+	assert.Equal(t, "ROLLBACK", conn.events[len(conn.events)-1])
+}
+
+func TestPreconditionIsCheckedByTheWrite(t *testing.T) {
+	db, conn := fakeDB(t, 0)
+	userId := uuid.New()
+	updatedAt := time.UnixMicro(1700000000000001)
+	ctx := repository.WithPrecondition(context.Background(), repository.Precondition{Id: userId, UpdatedAt: updatedAt})
+	users := repository.NewSystemUserRepository(db).(*repository.SystemUserRepository).WithContext(ctx).(*repository.GormRepository[model.SystemUser, uuid.UUID, repository.SystemUserSearch])
+
+	_, err := users.Update(nil, nil, &model.SystemUser{UserId: userId, UserName: "john"})
+	assert.ErrorIs(t, err, repository.ErrPreconditionFailed)
+	assert.Contains(t, conn.statement(`UPDATE "system_users" SET`), `"system_users"."date_updated" = $`)
+
+	err = users.Purge(nil, nil, []uuid.UUID{userId})
+	assert.ErrorIs(t, err, repository.ErrPreconditionFailed)
+	assert.Contains(t, conn.statement(`DELETE FROM "system_users"`), `"system_users"."date_updated" = $`)
+
+	// other entities are written unconditionally
+	err = users.Purge(nil, nil, []uuid.UUID{uuid.New()})
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+func TestETagIdentifiesTheProjection(t *testing.T) {
+	router, path, _ := storedOrganizationRouter(t, 3, 1)
+
+	whole := conditionalRequest(router, http.MethodGet, path, "If-None-Match", `"v2"`, "").Header().Get("ETag")
+	projected := conditionalRequest(router, http.MethodGet, path+"?fields=id,name", "If-None-Match", `"v2"`, "").Header().Get("ETag")
+	other := conditionalRequest(router, http.MethodGet, path+"?fields=id", "If-None-Match", `"v2"`, "").Header().Get("ETag")
+
+	assert.Equal(t, `"v3"`, whole)
+	assert.True(t, strings.HasPrefix(projected, `"v3-`), projected)
+	assert.NotEqual(t, projected, other)
+
+	resp := conditionalRequest(router, http.MethodGet, path+"?fields=id,name", "If-None-Match", whole, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	resp = conditionalRequest(router, http.MethodGet, path+"?fields=id,name", "If-None-Match", projected, "")
+	assert.Equal(t, http.StatusNotModified, resp.Code)
+}
+
+func TestETagFallsBackToUpdateTime(t *testing.T) {
+	user := &model.SystemUser{DateUpdated: time.UnixMicro(1700000000000001)}
+
+	assert.Equal(t, `"t1700000000000001"`, controller.ETagOf(user))
+	assert.Equal(t, `"v4"`, controller.ETagOf(&model.Organization{IdentifiedModel: model.IdentifiedModel{Version: 4}}))
+	assert.Empty(t, controller.ETagOf(&model.SystemUser{}))
+}
@@ -0,0 +1,52 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/roksky/bootstrap-api/data/response"
+	"github.com/roksky/bootstrap-api/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFieldsSelectRequestedColumns(t *testing.T) {
+	db, recorder := dryRunDB(t)
+
+	search := &repository.OrganizationSearch{}
+	search.Fields = "name, dateUpdated"
+	_, err := repository.NewOrganizationRepository(db).FindByIds(nil, search, []uuid.UUID{uuid.New()})
+
+	assert.NoError(t, err)
+	assert.Contains(t, recorder.last(), `SELECT "id","name","date_updated" FROM "organizations"`)
+	assert.Equal(t, []string{"name", "dateUpdated"}, repository.ProjectedFields(search))
+}
+
+func TestFieldsKeepKeysOfIncludedRelations(t *testing.T) {
+	db, recorder := dryRunDB(t)
+
+	search := &repository.SystemUserOrganizationSearch{}
+	search.Fields = "userRole"
+	search.Include = "systemUser"
+	search.PageSize = 10
+	_, err := repository.NewSystemUserOrganizationRepository(db).Search(nil, search)
+
+	assert.NoError(t, err)
+	assert.Contains(t, recorder.last(), `SELECT "id","user_role","system_user" FROM "system_user_organizations"`)
+	assert.Equal(t, []string{"userRole", "systemUser"}, repository.ProjectedFields(search))
+}
+
+func TestFieldsRejectUnknownFields(t *testing.T) {
+	router := organizationRouter(t)
+
+	req, _ := http.NewRequest(http.MethodGet, "/org?fields=id,secret", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	var problem response.Problem
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &problem))
+	assert.Contains(t, problem.Detail, `unknown field "secret"`)
+}
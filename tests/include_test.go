@@ -0,0 +1,59 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/roksky/bootstrap-api/data/response"
+	"github.com/roksky/bootstrap-api/repository"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestIncludePreloadsWhitelistedRelations(t *testing.T) {
+	db, _ := dryRunDB(t)
+	var preloaded []string
+	err := db.Callback().Query().Before("gorm:query").Register("test:preloads", func(db *gorm.DB) {
+		for path := range db.Statement.Preloads {
+			preloaded = append(preloaded, path)
+		}
+	})
+	assert.NoError(t, err)
+
+	search := &repository.SystemUserOrganizationSearch{}
+	search.Include = "systemUser, organization"
+	_, err = repository.NewSystemUserOrganizationRepository(db).Search(nil, search)
+
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"SystemUser", "Organization"}, preloaded)
+}
+
+func TestIncludeRejectsUnknownRelations(t *testing.T) {
+	db, recorder := dryRunDB(t)
+
+	search := &repository.SystemUserOrganizationSearch{}
+	search.Include = "systemUser,password"
+	_, err := repository.NewSystemUserOrganizationRepository(db).Search(nil, search)
+
+	var includeError *repository.InvalidIncludeError
+	if assert.ErrorAs(t, err, &includeError) {
+		assert.Equal(t, "password", includeError.Relation)
+		assert.Equal(t, []string{"organization", "systemUser"}, includeError.Allowed)
+	}
+	assert.Empty(t, recorder.statements)
+}
+
+func TestIncludeIsDeniedWithoutRegistry(t *testing.T) {
+	router := organizationRouter(t)
+
+	req, _ := http.NewRequest(http.MethodGet, "/org?include=members", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	var problem response.Problem
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &problem))
+	assert.Contains(t, problem.Detail, `cannot include "members"`)
+}
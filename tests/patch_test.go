@@ -0,0 +1,198 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/roksky/bootstrap-api/apperror"
+	"github.com/roksky/bootstrap-api/controller"
+	"github.com/roksky/bootstrap-api/helper"
+	"github.com/roksky/bootstrap-api/model"
+	"github.com/roksky/bootstrap-api/repository"
+	"github.com/roksky/bootstrap-api/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func jsonPatch(t *testing.T, operations string) []helper.PatchOperation {
+	var patch []helper.PatchOperation
+	assert.NoError(t, json.Unmarshal([]byte(operations), &patch))
+	return patch
+}
+
+func TestJSONPatch(t *testing.T) {
+	target := map[string]interface{}{
+		"name":    "Acme",
+		"tags":    []interface{}{"b", "c"},
+		"address": map[string]interface{}{"city": "Paris", "zip": "75001"},
+	}
+
+	patched, err := helper.JSONPatch(target, jsonPatch(t, `[
+		{"op": "test", "path": "/name", "value": "Acme"},
+		{"op": "replace", "path": "/name", "value": ""},
+		{"op": "add", "path": "/tags/0", "value": "a"},
+		{"op": "add", "path": "/tags/-", "value": "d"},
+		{"op": "remove", "path": "/address/zip"},
+		{"op": "copy", "from": "/address/city", "path": "/city"},
+		{"op": "move", "from": "/address", "path": "/headquarters"},
+		{"op": "add", "path": "/active", "value": false}
+	]`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"name":         "",
+		"tags":         []interface{}{"a", "b", "c", "d"},
+		"city":         "Paris",
+		"headquarters": map[string]interface{}{"city": "Paris"},
+		"active":       false,
+	}, patched)
+	assert.Equal(t, "Acme", target["name"], "the target is left unchanged")
+	assert.Equal(t, "75001", target["address"].(map[string]interface{})["zip"])
+}
+
+func TestJSONPatchFailures(t *testing.T) {
+	target := map[string]interface{}{"name": "Acme"}
+
+	_, err := helper.JSONPatch(target, jsonPatch(t, `[{"op": "test", "path": "/name", "value": "Other"}]`))
+	assert.Equal(t, apperror.KindConflict, apperror.From(err).Kind)
+
+	_, err = helper.JSONPatch(target, jsonPatch(t, `[{"op": "remove", "path": "/missing"}]`))
+	assert.Equal(t, "invalid_patch", apperror.From(err).Code)
+
+	_, err = helper.JSONPatch(target, jsonPatch(t, `[{"op": "add", "path": "/name"}]`))
+	assert.Equal(t, "invalid_patch", apperror.From(err).Code)
+}
+
+func TestReplaceWritesZeroValues(t *testing.T) {
+	db, recorder := dryRunDB(t)
+	organizationRepository := repository.NewOrganizationRepository(db).(*repository.OrganizationRepository)
+	organization := &model.Organization{IdentifiedModel: model.IdentifiedModel{Id: uuid.New(), Version: 3}}
+
+	_, _ = organizationRepository.Replace(nil, nil, organization)
+
+	update := recorder.statements[0]
+	assert.True(t, strings.HasPrefix(update, `UPDATE "organizations" SET`), update)
+	assert.Contains(t, update, `"name"=''`)
+	assert.Contains(t, update, `"version"=4`)
+	assert.Contains(t, update, `"version" = 3`)
+	assert.NotContains(t, update, "date_created")
+	assert.NotContains(t, update, "created_by")
+	assert.NotContains(t, update, "deleted_by")
+}
+
+func TestReplaceKeepsFieldsLeftOutOfJson(t *testing.T) {
+	db, recorder := dryRunDB(t)
+	memberships := repository.NewSystemUserOrganizationRepository(db).(*repository.SystemUserOrganizationRepository)
+	membership := &model.SystemUserOrganization{
+		IdentifiedModel: model.IdentifiedModel{Id: uuid.New()},
+		Organization:    model.Organization{IdentifiedModel: model.IdentifiedModel{Id: uuid.New()}, Name: "Acme"},
+		UserRole:        model.Admin,
+	}
+
+	_, _ = memberships.Replace(nil, nil, membership)
+
+	update := recorder.statements[0]
+	assert.True(t, strings.HasPrefix(update, `UPDATE "system_user_organizations" SET`), update)
+	assert.Contains(t, update, `"user_role"='admin'`)
+	assert.NotContains(t, update, `"system_user"=`)
+	assert.NotContains(t, update, `"organization"=`)
+	for _, statement := range recorder.statements {
+		assert.NotContains(t, statement, `INSERT INTO "organizations"`)
+	}
+}
+
+func TestUpdateLeavesAssociationsAlone(t *testing.T) {
+	for _, version := range []int64{0, 2} {
+		db, conn := fakeDB(t, 1)
+		memberships := repository.NewSystemUserOrganizationRepository(db)
+		membership := &model.SystemUserOrganization{
+			IdentifiedModel: model.IdentifiedModel{Id: uuid.New(), Version: version},
+			OrganizationId:  uuid.New(),
+			Organization:    model.Organization{IdentifiedModel: model.IdentifiedModel{Id: uuid.New()}, Name: "Acme"},
+			UserRole:        model.Admin,
+		}
+
+		_, _ = memberships.Update(nil, nil, membership)
+
+		update := conn.statement(`UPDATE "system_user_organizations" SET`)
+		assert.Contains(t, update, `"user_role"=`)
+		for _, statement := range conn.events {
+			assert.NotContains(t, statement, `INSERT INTO "organizations"`)
+			assert.NotContains(t, statement, `"organization"=`)
+		}
+	}
+}
+
+func TestPatchRejectsUnknownContentType(t *testing.T) {
+	router, _ := organizationWriteRouter(t)
+
+	req, _ := http.NewRequest(http.MethodPatch, "/org/"+uuid.NewString(), strings.NewReader(`name=Acme`))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, resp.Code)
+}
+
+// replacedOrganizationService serves the stored organization and records the replacements.
+type replacedOrganizationService struct {
+	*storedOrganizationService
+	replaced []*model.Organization
+}
+
+func (s *replacedOrganizationService) WithContext(context.Context) service.BaseService[model.Organization, uuid.UUID, repository.OrganizationSearch] {
+	return s
+}
+
+func (s *replacedOrganizationService) Replace(_ *repository.OrganizationSearch, item *model.Organization) (*model.Organization, error) {
+	s.replaced = append(s.replaced, item)
+	return item, nil
+}
+
+func replacedOrganizationRouter() (*gin.Engine, string, *replacedOrganizationService) {
+	organization := &model.Organization{IdentifiedModel: model.IdentifiedModel{Id: uuid.New(), Version: 3}, Name: "Acme"}
+	organizationService := &replacedOrganizationService{storedOrganizationService: &storedOrganizationService{organization: organization}}
+	router := controllerRouter(controller.NewOrganizationController(organizationService), withToken(""))
+	return router, "/org/" + organization.Id.String(), organizationService
+}
+
+func TestReplaceThroughRequestMapperClearsAbsentFields(t *testing.T) {
+	router, path, organizationService := replacedOrganizationRouter()
+
+	req, _ := http.NewRequest(http.MethodPut, path, strings.NewReader(`{}`))
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	if assert.Len(t, organizationService.replaced, 1) {
+		replaced := organizationService.replaced[0]
+		assert.Equal(t, "", replaced.Name)
+		assert.Equal(t, path, "/org/"+replaced.Id.String())
+		assert.Equal(t, int64(3), replaced.Version)
+	}
+}
+
+func TestMergePatchThroughRequestMapperClearsNullFields(t *testing.T) {
+	router, path, organizationService := replacedOrganizationRouter()
+
+	resp := conditionalRequest(router, http.MethodPatch, path, "If-Match", `"v3"`, `{"name": null}`)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	if assert.Len(t, organizationService.replaced, 1) {
+		replaced := organizationService.replaced[0]
+		assert.Equal(t, "", replaced.Name)
+		assert.Equal(t, int64(3), replaced.Version)
+	}
+
+	resp = conditionalRequest(router, http.MethodPatch, path, "If-Match", `"v3"`, `{"version": 3}`)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	if assert.Len(t, organizationService.replaced, 2) {
+		assert.Equal(t, "Acme", organizationService.replaced[1].Name)
+	}
+}
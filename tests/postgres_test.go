@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/roksky/bootstrap-api/database"
+	"github.com/roksky/bootstrap-api/model"
+	"github.com/roksky/bootstrap-api/repository"
+	"github.com/roksky/bootstrap-api/service"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// postgresDB returns a gorm.DB on a schema of its own of the database of TEST_DATABASE_URL, migrated
+// and dropped once the test is over. The test is skipped when TEST_DATABASE_URL is not set.
+func postgresDB(t *testing.T) *gorm.DB {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	admin, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("Couldn't open test database: %v\n", err)
+	}
+	schemaName := "test_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	if err = admin.Exec(fmt.Sprintf("CREATE SCHEMA %s", schemaName)).Error; err != nil {
+		t.Fatalf("Couldn't create test schema: %v\n", err)
+	}
+
+	db, err := gorm.Open(postgres.Open(withSearchPath(dsn, schemaName)), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("Couldn't open test schema: %v\n", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+		admin.Exec(fmt.Sprintf("DROP SCHEMA %s CASCADE", schemaName))
+		if sqlDB, err := admin.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+
+	if err = db.AutoMigrate(&model.SystemUser{}); err != nil {
+		t.Fatalf("Couldn't migrate test schema: %v\n", err)
+	}
+	if err = database.NewDatabase(db).AutoMigrate(); err != nil {
+		t.Fatalf("Couldn't migrate test schema: %v\n", err)
+	}
+	return db
+}
+
+// withSearchPath sets the search_path runtime parameter of the connections of dsn, given as a URL
+// or as key/value pairs.
+func withSearchPath(dsn string, schemaName string) string {
+	parsed, err := url.Parse(dsn)
+	if err != nil || parsed.Scheme == "" {
+		return dsn + " search_path=" + schemaName
+	}
+	query := parsed.Query()
+	query.Set("search_path", schemaName)
+	parsed.RawQuery = query.Encode()
+	return parsed.String()
+}
+
+func TestTenantScopeOnPostgres(t *testing.T) {
+	db := postgresDB(t)
+	assert.NoError(t, repository.RegisterTenantScope(db))
+	repo := repository.NewOrganizationRepository(db)
+	acme, err := repo.Save(nil, nil, &model.Organization{Name: "acme"})
+	assert.NoError(t, err)
+	globex, err := repo.Save(nil, nil, &model.Organization{Name: "globex"})
+	assert.NoError(t, err)
+
+	scoped := repository.Bind(repo, repository.WithTenant(context.Background(), acme.Id))
+	found, err := scoped.Search(nil, &repository.OrganizationSearch{})
+	assert.NoError(t, err)
+	if assert.Len(t, found, 1) {
+		assert.Equal(t, acme.Id, found[0].Id)
+	}
+	_, err = scoped.FindById(nil, nil, globex.Id)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	_, err = repository.Bind(repo, repository.RequireTenant(context.Background())).Search(nil, &repository.OrganizationSearch{})
+	assert.ErrorIs(t, err, repository.ErrTenantRequired)
+
+	all, err := repo.Search(nil, &repository.OrganizationSearch{})
+	assert.NoError(t, err)
+	assert.Len(t, all, 2)
+}
+
+func TestDuplicatePreferencesAreUniqueViolationsOnPostgres(t *testing.T) {
+	db := postgresDB(t)
+	user := &model.SystemUser{UserName: "alice"}
+	assert.NoError(t, db.Create(user).Error)
+	repo := repository.NewUserPreferencesRepository(db)
+
+	_, err := repo.Save(nil, nil, &model.UserPreferences{SystemUserId: user.UserId})
+	assert.NoError(t, err)
+	_, err = repo.Save(nil, nil, &model.UserPreferences{SystemUserId: user.UserId})
+	assert.True(t, repository.IsUniqueViolation(err), "%v", err)
+}
+
+func TestConcurrentFirstPreferencesSavesOnPostgres(t *testing.T) {
+	db := postgresDB(t)
+	user := &model.SystemUser{UserName: "alice"}
+	assert.NoError(t, db.Create(user).Error)
+	preferencesService := service.NewUserPreferencesService(repository.NewUserPreferencesRepository(db))
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = preferencesService.Patch(user.UserId, map[string]interface{}{fmt.Sprint("key", i): i})
+		}()
+	}
+	wg.Wait()
+
+	assert.NoError(t, errs[0])
+	assert.NoError(t, errs[1])
+	var count int64
+	assert.NoError(t, db.Model(&model.UserPreferences{}).Where("system_user = ?", user.UserId).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+	preferences, err := preferencesService.Get(user.UserId)
+	assert.NoError(t, err)
+	assert.Contains(t, preferences, "key0")
+	assert.Contains(t, preferences, "key1")
+}
+
+func TestChangesReportPurgedEntitiesOnPostgres(t *testing.T) {
+	db := postgresDB(t)
+	repo := repository.NewOrganizationRepository(db).(*repository.OrganizationRepository)
+	since := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339Nano)
+	kept, err := repo.Save(nil, nil, &model.Organization{Name: "acme"})
+	assert.NoError(t, err)
+	purged, err := repo.Save(nil, nil, &model.Organization{Name: "globex"})
+	assert.NoError(t, err)
+
+	err = repo.WithContext(repository.WithActor(context.Background(), "admin")).(repository.RestoreRepository[model.Organization, uuid.UUID, repository.OrganizationSearch]).
+		Purge(nil, nil, []uuid.UUID{purged.Id})
+	assert.NoError(t, err)
+
+	changes, err := repo.Changes(nil, &repository.OrganizationSearch{}, since)
+	assert.NoError(t, err)
+	if assert.Len(t, changes.Upserted, 1) {
+		assert.Equal(t, kept.Id, changes.Upserted[0].Id)
+	}
+	if assert.Len(t, changes.Deleted, 1) {
+		assert.Equal(t, purged.Id.String(), changes.Deleted[0].Id)
+		assert.Equal(t, "admin", changes.Deleted[0].DeletedBy)
+	}
+
+	// the sync token resumes after the purge
+	changes, err = repo.Changes(nil, &repository.OrganizationSearch{}, changes.SyncToken)
+	assert.NoError(t, err)
+	assert.Empty(t, changes.Upserted)
+	assert.Empty(t, changes.Deleted)
+}
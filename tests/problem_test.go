@@ -0,0 +1,168 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/roksky/bootstrap-api/apperror"
+	"github.com/roksky/bootstrap-api/constants"
+	"github.com/roksky/bootstrap-api/controller"
+	"github.com/roksky/bootstrap-api/data/response"
+	"github.com/roksky/bootstrap-api/helper"
+	"github.com/roksky/bootstrap-api/repository"
+	"github.com/roksky/bootstrap-api/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+func TestErrorsAreRenderedAsProblems(t *testing.T) {
+	router := organizationRouter(t)
+
+	req, _ := http.NewRequest(http.MethodGet, "/org/not-an-id", nil)
+	req.Header.Set(constants.RequestIdHeader, "request-1")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, response.ProblemContentType, resp.Header().Get("Content-Type"))
+	var problem response.Problem
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &problem))
+	assert.Equal(t, response.Problem{
+		Type:      "about:blank",
+		Title:     "Bad Request",
+		Status:    http.StatusBadRequest,
+		Code:      "invalid_id",
+		Detail:    "not-an-id is not a valid id",
+		Instance:  "/org/not-an-id",
+		RequestId: "request-1",
+	}, problem)
+}
+
+func TestTypedErrorsKeepTheirKindWhenWrapped(t *testing.T) {
+	notFound := apperror.From(fmt.Errorf("loading organization: %w", repository.ErrNotFound))
+	assert.Equal(t, apperror.KindNotFound, notFound.Kind)
+	assert.Equal(t, "not_found", notFound.Code)
+
+	conflict := apperror.From(&repository.VersionConflictError{CurrentVersion: 4})
+	assert.Equal(t, apperror.KindConflict, conflict.Kind)
+	assert.Equal(t, int64(4), conflict.Extensions["currentVersion"])
+
+	internal := apperror.From(errors.New("connection refused"))
+	assert.Equal(t, apperror.KindInternal, internal.Kind)
+	assert.NotContains(t, internal.Detail, "connection refused")
+}
+
+type signUp struct {
+	Email   string `json:"email" validate:"required,email"`
+	Age     int    `json:"age" validate:"gte=18"`
+	Address struct {
+		City string `json:"city" validate:"required"`
+	} `json:"address"`
+}
+
+func problemIn(acceptLanguage string, err error) response.Problem {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/sign-up", nil)
+	ctx.Request.Header.Set("Accept-Language", acceptLanguage)
+	return controller.ProblemOf(ctx, err)
+}
+
+func TestValidationErrorsListTheirJsonFields(t *testing.T) {
+	err := apperror.Validator().Struct(signUp{Email: "john", Age: 17})
+
+	problem := problemIn("", err)
+
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+	assert.Equal(t, "validation_failed", problem.Code)
+	assert.Equal(t, []response.FieldProblem{
+		{Field: "email", Rule: "email", Message: "email must be a valid email address"},
+		{Field: "age", Rule: "gte", Param: "18", Message: "age must be 18 or greater"},
+		{Field: "address.city", Rule: "required", Message: "city is a required field"},
+	}, problem.Errors)
+}
+
+func TestServicesReportJsonFieldsWithAnyValidator(t *testing.T) {
+	for _, validate := range []*validator.Validate{validator.New(), nil} {
+		signUps := service.NewCrudService[signUp, uuid.UUID, struct{}](nil, validate)
+
+		_, err := signUps.Create(nil, &signUp{Email: "john@example.com", Age: 18})
+
+		if assert.Len(t, problemIn("", err).Errors, 1) {
+			assert.Equal(t, "address.city", problemIn("", err).Errors[0].Field)
+			assert.Equal(t, "city is a required field", problemIn("", err).Errors[0].Message)
+		}
+	}
+}
+
+func TestServicesLeaveTheirValidatorUnchanged(t *testing.T) {
+	validate := validator.New()
+	service.NewCrudService[signUp, uuid.UUID, struct{}](nil, validate)
+
+	var validationErrors validator.ValidationErrors
+	if assert.ErrorAs(t, validate.Struct(signUp{Email: "john@example.com", Age: 18}), &validationErrors) {
+		assert.Equal(t, "City", validationErrors[0].Field())
+	}
+}
+
+func TestValidationMessagesFollowAcceptLanguage(t *testing.T) {
+	err := apperror.Validator().Struct(signUp{Email: "john@example.com", Age: 18})
+
+	assert.Equal(t, "city est un champ obligatoire", problemIn("fr-CH, fr;q=0.9, en;q=0.8", err).Errors[0].Message)
+	assert.Equal(t, "city is a required field", problemIn("ja, en;q=0.5", err).Errors[0].Message)
+	assert.Equal(t, "city is a required field", problemIn("fr;q=0, en", err).Errors[0].Message)
+}
+
+func TestSchemaErrorsAreReportedAsFields(t *testing.T) {
+	schema := gojsonschema.NewStringLoader(`{
+		"type": "object",
+		"required": ["name"],
+		"properties": {"status": {"enum": ["active", "closed"]}}
+	}`)
+	result, err := gojsonschema.Validate(schema, gojsonschema.NewStringLoader(`{"status": "open"}`))
+	assert.NoError(t, err)
+
+	problem := problemIn("de", &helper.GroupedError{Errors: result.Errors()})
+
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+	assert.ElementsMatch(t, []response.FieldProblem{
+		{Field: "name", Rule: "required", Message: "name ist ein Pflichtfeld"},
+		{Field: "status", Rule: "oneof", Param: `"active", "closed"`, Message: `status muss einer der folgenden sein: ["active", "closed"]`},
+	}, problem.Errors)
+}
+
+func TestProblemExtensionsAreTopLevelMembers(t *testing.T) {
+	problem := response.Problem{Status: http.StatusConflict, Code: "version_conflict",
+		Extensions: map[string]interface{}{"currentVersion": 4, "code": "overridden"}}
+
+	data, err := json.Marshal(problem)
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type":"","title":"","status":409,"code":"version_conflict","currentVersion":4}`, string(data))
+}
+
+func TestCancelledAndTimedOutRequestsAreNotInternalErrors(t *testing.T) {
+	assert.Equal(t, apperror.KindCanceled, apperror.From(fmt.Errorf("query: %w", context.Canceled)).Kind)
+	assert.Equal(t, apperror.KindTimeout, apperror.From(fmt.Errorf("query: %w", context.DeadlineExceeded)).Kind)
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/org", nil).WithContext(cancelled)
+	problem := controller.ProblemOf(ctx, errors.New("conn closed"))
+	assert.Equal(t, controller.StatusClientClosedRequest, problem.Status)
+	assert.Equal(t, "Client Closed Request", problem.Title)
+
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/org", nil).WithContext(expired)
+	assert.Equal(t, http.StatusGatewayTimeout, controller.ProblemOf(ctx, errors.New("conn closed")).Status)
+}
@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/roksky/bootstrap-api/constants"
+	"github.com/roksky/bootstrap-api/controller"
+	"github.com/roksky/bootstrap-api/repository"
+	"github.com/roksky/bootstrap-api/service"
+	"github.com/stretchr/testify/assert"
+)
+
+// deletedOrganizationRouter serves the organizations to a user granted scope, their writes
+// affecting rowsAffected rows.
+func deletedOrganizationRouter(t *testing.T, scope string, rowsAffected int64) (*gin.Engine, *fakeConn) {
+	db, conn := fakeDB(t, rowsAffected)
+	organizationService := service.NewOrganizationService(repository.NewOrganizationRepository(db), validator.New())
+	return controllerRouter(controller.NewOrganizationController(organizationService), withToken(scope)), conn
+}
+
+func serve(router *gin.Engine, method string, path string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestPurgeRequiresAdminScope(t *testing.T) {
+	router, conn := deletedOrganizationRouter(t, "read", 1)
+
+	resp := serve(router, http.MethodDelete, "/org/"+uuid.NewString()+"?hard=true")
+
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	assert.Empty(t, conn.events)
+}
+
+func TestPurge(t *testing.T) {
+	router, conn := deletedOrganizationRouter(t, constants.AdminScope, 1)
+
+	resp := serve(router, http.MethodDelete, "/org/"+uuid.NewString()+"?hard=true")
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.NotEmpty(t, conn.statement(`DELETE FROM "organizations" WHERE "organizations"."id" = $1`))
+}
+
+func TestPurgeMissingEntity(t *testing.T) {
+	router, _ := deletedOrganizationRouter(t, constants.AdminScope, 0)
+
+	resp := serve(router, http.MethodDelete, "/org/"+uuid.NewString()+"?hard=true")
+
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestRestore(t *testing.T) {
+	db, conn := fakeDB(t, 1)
+
+	err := repository.NewOrganizationRepository(db).(*repository.OrganizationRepository).Restore(nil, nil, []uuid.UUID{uuid.New()})
+
+	assert.NoError(t, err)
+	restore := conn.statement(`UPDATE "organizations" SET "date_deleted"=$1`)
+	assert.Contains(t, restore, `"organizations"."date_deleted" IS NOT NULL`)
+}
+
+func TestRestoreMissingEntity(t *testing.T) {
+	router, _ := deletedOrganizationRouter(t, "", 0)
+
+	resp := serve(router, http.MethodPost, "/org/"+uuid.NewString()+"/restore")
+
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
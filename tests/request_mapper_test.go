@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/roksky/bootstrap-api/controller"
+	"github.com/roksky/bootstrap-api/data/response"
+	"github.com/roksky/bootstrap-api/model"
+	"github.com/roksky/bootstrap-api/repository"
+	"github.com/roksky/bootstrap-api/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func organizationWriteRouter(t *testing.T) (*gin.Engine, *sqlRecorder) {
+	db, recorder := dryRunDB(t)
+	organizationController := controller.NewOrganizationController(
+		service.NewOrganizationService(repository.NewOrganizationRepository(db), validator.New()))
+	return controllerRouter(organizationController, withToken("")), recorder
+}
+
+func TestCreateDoesNotWriteServerManagedFields(t *testing.T) {
+	router, recorder := organizationWriteRouter(t)
+
+	body := `{"id": "6f1c1f0e-52a4-4b7e-9a4f-0c5e3a1f9d2b", "name": "Acme", "createdBy": "mallory",
+		"dateCreated": "2001-01-01T00:00:00Z", "dateDeleted": "2001-01-01T00:00:00Z"}`
+	req, _ := http.NewRequest(http.MethodPost, "/org", strings.NewReader(body))
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	insert := ""
+	for _, statement := range recorder.statements {
+		if strings.HasPrefix(statement, `INSERT INTO "organizations"`) {
+			insert = statement
+		}
+	}
+	assert.Contains(t, insert, "'Acme'")
+	assert.NotContains(t, insert, "mallory")
+	assert.NotContains(t, insert, "6f1c1f0e-52a4-4b7e-9a4f-0c5e3a1f9d2b")
+	assert.NotContains(t, insert, "2001-01-01")
+}
+
+func TestCreateValidatesTheRequestDto(t *testing.T) {
+	router, _ := organizationWriteRouter(t)
+
+	req, _ := http.NewRequest(http.MethodPost, "/org", strings.NewReader(`{"name": ""}`))
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	var problem response.Problem
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &problem))
+	assert.Equal(t, []response.FieldProblem{
+		{Field: "name", Rule: "required", Message: "name is a required field"},
+	}, problem.Errors)
+}
+
+func TestPartialBulkCreateReportsInvalidItems(t *testing.T) {
+	router, _ := organizationWriteRouter(t)
+
+	req, _ := http.NewRequest(http.MethodPost, "/org/s?mode=partial", strings.NewReader(`[{"name": ""}]`))
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusMultiStatus, resp.Code)
+	var results []response.BulkItemResult[*model.Organization]
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &results))
+	if assert.Len(t, results, 1) && assert.NotNil(t, results[0].Error) {
+		assert.Equal(t, http.StatusBadRequest, results[0].Status)
+		assert.Equal(t, "validation_failed", results[0].Error.Code)
+	}
+}
+
+func TestCreateIgnoresVersionAndAssociations(t *testing.T) {
+	db, recorder := dryRunDB(t)
+	memberships := controller.NewCrudController(
+		service.NewSystemUserOrganizationService(repository.NewSystemUserOrganizationRepository(db), validator.New()),
+		"/memberships", uuid.Parse, controller.BindSearch[repository.SystemUserOrganizationSearch])
+	router := controllerRouter(memberships, withToken(""))
+
+	body := `{"version": 7, "userRole": "owner", "organization": {"name": "Acme"}, "systemUser": {"userName": "mallory"}}`
+	req, _ := http.NewRequest(http.MethodPost, "/memberships", strings.NewReader(body))
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	insert := ""
+	for _, statement := range recorder.statements {
+		assert.NotContains(t, statement, `INSERT INTO "organizations"`)
+		assert.NotContains(t, statement, `INSERT INTO "system_users"`)
+		if strings.HasPrefix(statement, `INSERT INTO "system_user_organizations"`) {
+			insert = statement
+		}
+	}
+	assert.Contains(t, insert, `"version","system_user"`)
+	assert.Contains(t, insert, `'',1,'`)
+}
@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/roksky/bootstrap-api/helper"
+	"github.com/roksky/bootstrap-api/model"
+	"github.com/roksky/bootstrap-api/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+type membershipSearch struct {
+	repository.PageRequest
+	Name          string      `query:"name" filter:"column=name,op=ilike"`
+	Roles         []string    `query:"role" filter:"column=user_role,op=in"`
+	CreatedIn     []time.Time `query:"created" filter:"column=date_created,op=between"`
+	Organization  *uuid.UUID  `query:"organizationId" filter:"column=organization"`
+	NotDeleted    *bool       `query:"notDeleted" filter:"column=date_deleted,op=isnull"`
+	MinimumRating int         `query:"minRating" filter:"column=rating,op=gte"`
+}
+
+func TestBindQuery(t *testing.T) {
+	orgId := uuid.New()
+	values := url.Values{
+		"name":           {"acme"},
+		"role":           {"owner,admin", "member"},
+		"created":        {"2024-01-01,2024-12-31T23:59:59Z"},
+		"organizationId": {orgId.String()},
+		"notDeleted":     {"true"},
+	}
+
+	search := &membershipSearch{}
+	err := helper.BindQuery(values, search)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "acme", search.Name)
+	assert.Equal(t, []string{"owner", "admin", "member"}, search.Roles)
+	assert.Len(t, search.CreatedIn, 2)
+	assert.Equal(t, orgId, *search.Organization)
+	assert.True(t, *search.NotDeleted)
+	assert.Equal(t, 100, search.PageSize)
+	assert.Equal(t, 0, search.PageNumber)
+}
+
+func TestBindQueryRejectsInvalidValues(t *testing.T) {
+	search := &membershipSearch{}
+	err := helper.BindQuery(url.Values{"minRating": {"high"}}, search)
+
+	assert.ErrorContains(t, err, "minRating")
+}
+
+func TestApplySearchFilters(t *testing.T) {
+	orgId := uuid.New()
+	search := &membershipSearch{
+		Name:         "acme",
+		Roles:        []string{"owner", "admin"},
+		Organization: &orgId,
+		NotDeleted:   helper.AsPtr(false),
+	}
+
+	db, _ := dryRunDB(t)
+	stmt := repository.ApplySearchFilters(db, search).Find(&[]model.SystemUserOrganization{}).Statement
+
+	sql := stmt.SQL.String()
+	assert.Contains(t, sql, `"system_user_organizations"."name" ILIKE $1`)
+	assert.Contains(t, sql, `"system_user_organizations"."user_role" IN ($2,$3)`)
+	assert.Contains(t, sql, `"system_user_organizations"."organization" = $4`)
+	assert.Contains(t, sql, `"system_user_organizations"."date_deleted" IS NOT NULL`)
+	assert.NotContains(t, sql, "rating")
+	assert.Equal(t, "%acme%", stmt.Vars[0])
+}
+
+func TestSearchFiltersMatchWildcardsLiterally(t *testing.T) {
+	db, _ := dryRunDB(t)
+	stmt := repository.ApplySearchFilters(db, &membershipSearch{Name: `100%_\`}).Find(&[]model.SystemUserOrganization{}).Statement
+
+	assert.Contains(t, stmt.SQL.String(), `"system_user_organizations"."name" ILIKE $1 ESCAPE '\'`)
+	assert.Equal(t, `%100\%\_\\%`, stmt.Vars[0])
+}
+
+func TestSearchPageSizeIsBounded(t *testing.T) {
+	db, recorder := dryRunDB(t)
+	repo := repository.NewOrganizationRepository(db)
+
+	for pageSize, limit := range map[int]string{
+		-1:      "LIMIT 100",
+		0:       "LIMIT 100",
+		20:      "LIMIT 20",
+		1000000: "LIMIT 1000",
+	} {
+		search := &repository.OrganizationSearch{}
+		search.PageSize = pageSize
+		search.PageNumber = -2
+		_, err := repo.Search(nil, search)
+
+		assert.NoError(t, err)
+		assert.True(t, strings.HasSuffix(recorder.last(), limit), recorder.last())
+	}
+}
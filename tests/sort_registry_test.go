@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/roksky/bootstrap-api/model"
+	"github.com/roksky/bootstrap-api/repository"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+func TestSortRegistryParse(t *testing.T) {
+	registry := repository.NewSortRegistry(map[string]string{"name": "name", "dateCreated": "date_created"})
+
+	fields, err := registry.Parse("dateCreated:DESC, name")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []repository.SortField{
+		{Column: "date_created", Desc: true},
+		{Column: "name"},
+	}, fields)
+}
+
+func TestSortRegistryRejectsUnknownFields(t *testing.T) {
+	registry := repository.NewSortRegistry(map[string]string{"name": "name", "dateCreated": "date_created"})
+
+	for _, orderBy := range []string{"name; DROP TABLE organizations", "name:sideways", "createdBy"} {
+		_, err := registry.Parse(orderBy)
+
+		var sortError *repository.InvalidSortError
+		assert.ErrorAs(t, err, &sortError, orderBy)
+		assert.Equal(t, []string{"dateCreated", "name"}, sortError.Allowed)
+	}
+}
+
+func TestModelSortRegistryUsesJsonNames(t *testing.T) {
+	allowed := repository.ModelSortRegistry[model.SystemUserOrganization](nil).AllowedFields()
+
+	assert.Contains(t, allowed, "dateCreated")
+	assert.Contains(t, allowed, "userRole")
+	assert.NotContains(t, allowed, "systemUser")
+}
+
+func TestSearchRejectsUnknownSortField(t *testing.T) {
+	db, recorder := dryRunDB(t)
+	repo := repository.NewOrganizationRepository(db)
+
+	search := &repository.OrganizationSearch{}
+	search.OrderBy = "name desc; DELETE FROM organizations"
+	_, err := repo.Search(nil, search)
+
+	var sortError *repository.InvalidSortError
+	assert.ErrorAs(t, err, &sortError)
+	assert.Empty(t, recorder.statements)
+}
+
+func TestModelColumnsFollowTheNamingStrategyOfTheDatabase(t *testing.T) {
+	db := openDB(t, &fakeConn{}, &gorm.Config{DryRun: true, Logger: logger.Discard,
+		NamingStrategy: schema.NamingStrategy{NameReplacer: strings.NewReplacer("DateCreated", "CreatedAt")}})
+
+	assert.Equal(t, "created_at", repository.ModelColumns[model.Organization](db)["dateCreated"])
+	assert.Equal(t, "date_created", repository.ModelColumns[model.Organization](nil)["dateCreated"])
+
+	fields, err := repository.ModelSortRegistry[model.Organization](db).Parse("dateCreated")
+	assert.NoError(t, err)
+	assert.Equal(t, "created_at", fields[0].Column)
+}
@@ -0,0 +1,164 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-oauth2/oauth2/v4/models"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/roksky/bootstrap-api/constants"
+	"github.com/roksky/bootstrap-api/controller"
+	"github.com/roksky/bootstrap-api/model"
+	"github.com/roksky/bootstrap-api/repository"
+	"github.com/roksky/bootstrap-api/router"
+	"github.com/roksky/bootstrap-api/service"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// withToken authenticates the requests as a user granted the given scope.
+func withToken(scope string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set(constants.TokenKey, &models.Token{UserID: uuid.NewString(), Scope: scope})
+	}
+}
+
+func systemUserRouter(t *testing.T, scope string) (*gin.Engine, *sqlRecorder) {
+	db, recorder := dryRunDB(t)
+	systemUserService := service.NewSystemUserCrudService(repository.NewSystemUserRepository(db),
+		repository.NewOrganizationRepository(db), repository.NewSystemUserOrganizationRepository(db), validator.New())
+	return controllerRouter(controller.NewSystemUserController(systemUserService), withToken(scope)), recorder
+}
+
+func TestSystemUserSearchFilters(t *testing.T) {
+	db, recorder := dryRunDB(t)
+	repo := repository.NewSystemUserRepository(db)
+	active := true
+	organizationId := uuid.New()
+
+	search := &repository.SystemUserSearch{
+		UserNamePrefix:      "jo",
+		PrimaryOrganization: organizationId,
+		DateCreated:         []time.Time{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		Active:              &active,
+	}
+	search.PageSize = 10
+	_, err := repo.Search(nil, search)
+
+	assert.NoError(t, err)
+	sql := recorder.last()
+	assert.Contains(t, sql, `"system_users"."user_name" LIKE 'jo%'`)
+	assert.Contains(t, sql, `"system_users"."primary_organization" = '`+organizationId.String()+`'`)
+	assert.Contains(t, sql, `"system_users"."date_created" BETWEEN`)
+	assert.Contains(t, sql, `"system_users"."active" = true`)
+	assert.Contains(t, sql, `"system_users"."date_deleted" IS NULL`)
+}
+
+func TestSystemUserDeleteUsesUserIdColumn(t *testing.T) {
+	db, recorder := dryRunDB(t)
+	userId := uuid.New()
+
+	err := repository.NewSystemUserRepository(db).Delete(nil, nil, userId)
+
+	assert.NoError(t, err)
+	assert.Contains(t, recorder.last(), `UPDATE "system_users" SET "date_deleted"=`)
+	assert.Contains(t, recorder.last(), `"system_users"."user_id" = '`+userId.String()+`'`)
+}
+
+func TestSystemUserLookups(t *testing.T) {
+	db, _ := fakeDB(t, 0)
+	users := service.NewSystemUserService(repository.NewSystemUserRepo(db),
+		repository.NewOrganizationRepository(db), repository.NewSystemUserOrganizationRepository(db), validator.New())
+
+	// the deprecated lookups return an empty user when there is none
+	user, err := users.GetUserByUserName("john")
+	assert.NoError(t, err)
+	assert.Equal(t, &model.SystemUser{}, user)
+	user, err = users.GetByUserId(uuid.New())
+	assert.NoError(t, err)
+	assert.Equal(t, &model.SystemUser{}, user)
+
+	_, err = users.FindByUserName("john")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	_, err = users.FindByUserId(uuid.New())
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+func TestDeactivateUserRequiresAdminScope(t *testing.T) {
+	router, recorder := systemUserRouter(t, "read")
+
+	req, _ := http.NewRequest(http.MethodPost, "/users/"+uuid.NewString()+"/deactivate", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	assert.Empty(t, recorder.statements)
+}
+
+func TestDeactivateUser(t *testing.T) {
+	router, recorder := systemUserRouter(t, constants.AdminScope)
+	userId := uuid.New()
+
+	req, _ := http.NewRequest(http.MethodPost, "/users/"+userId.String()+"/deactivate", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	if assert.NotEmpty(t, recorder.statements) {
+		update := recorder.statements[0]
+		assert.Contains(t, update, `UPDATE "system_users" SET`)
+		assert.Contains(t, update, `"active"=false`)
+		assert.Contains(t, update, `"user_id" = '`+userId.String()+`'`)
+	}
+}
+
+// storedUsers serves the system users from a map.
+type storedUsers struct {
+	router.UserRepository
+	users map[uuid.UUID]*model.SystemUser
+}
+
+func (s *storedUsers) WithContext(ctx context.Context) router.UserRepository {
+	return s
+}
+
+func (s *storedUsers) FindById(tx *gorm.DB, searchParams *repository.SystemUserSearch, itemId uuid.UUID) (*model.SystemUser, error) {
+	if user, ok := s.users[itemId]; ok {
+		return user, nil
+	}
+	return nil, repository.ErrNotFound
+}
+
+func TestInactiveUsersAreRejected(t *testing.T) {
+	active, inactive := true, false
+	activeId, inactiveId := uuid.New(), uuid.New()
+	users := &storedUsers{users: map[uuid.UUID]*model.SystemUser{
+		activeId:   {UserId: activeId, Active: &active},
+		inactiveId: {UserId: inactiveId, Active: &inactive},
+	}}
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/org", func(c *gin.Context) {
+		c.Set(constants.TokenKey, &models.Token{UserID: c.GetHeader("X-User")})
+	}, router.ActiveUserMiddleware(users), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for userId, status := range map[string]int{
+		activeId.String():   http.StatusOK,
+		inactiveId.String(): http.StatusForbidden,
+		uuid.NewString():    http.StatusOK,
+		"client":            http.StatusOK,
+	} {
+		req, _ := http.NewRequest(http.MethodGet, "/org", nil)
+		req.Header.Set("X-User", userId)
+		resp := httptest.NewRecorder()
+		engine.ServeHTTP(resp, req)
+		assert.Equal(t, status, resp.Code, userId)
+	}
+}
@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/roksky/bootstrap-api/constants"
+	"github.com/roksky/bootstrap-api/controller"
+	"github.com/roksky/bootstrap-api/model"
+	"github.com/roksky/bootstrap-api/repository"
+	"github.com/roksky/bootstrap-api/router"
+	"github.com/roksky/bootstrap-api/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenantScopeFiltersQueries(t *testing.T) {
+	db, recorder := dryRunDB(t)
+	assert.NoError(t, repository.RegisterTenantScope(db))
+	repo := repository.NewSystemUserOrganizationRepository(db)
+
+	tenant := uuid.New()
+	_, err := repository.Bind(repo, repository.WithTenant(context.Background(), tenant)).FindByIds(nil, nil, []uuid.UUID{uuid.New()})
+
+	assert.NoError(t, err)
+	assert.Contains(t, recorder.last(), `"system_user_organizations"."organization" = '`+tenant.String()+`'`)
+}
+
+func TestTenantScopeDeniesRequestsWithoutOrganization(t *testing.T) {
+	db, recorder := dryRunDB(t)
+	assert.NoError(t, repository.RegisterTenantScope(db))
+	repo := repository.NewSystemUserOrganizationRepository(db)
+	ctx := repository.RequireTenant(context.Background())
+
+	_, err := repository.Bind(repo, ctx).FindByIds(nil, nil, []uuid.UUID{uuid.New()})
+	assert.ErrorIs(t, err, repository.ErrTenantRequired)
+
+	_, err = repository.Bind(repo, repository.WithoutTenantScope(ctx)).FindByIds(nil, nil, []uuid.UUID{uuid.New()})
+	assert.NoError(t, err)
+
+	// the statements outside of requests, e.g. of jobs, are not scoped
+	_, err = repo.FindByIds(nil, nil, []uuid.UUID{uuid.New()})
+	assert.NoError(t, err)
+	assert.NotContains(t, recorder.last(), `"organization" =`)
+}
+
+func TestAuditLogIsReadAcrossTenants(t *testing.T) {
+	db, recorder := dryRunDB(t)
+	assert.NoError(t, repository.RegisterTenantScope(db))
+	auditLogService := service.NewAuditLogService(repository.NewAuditLogRepository(db), validator.New())
+	requireTenant := func(c *gin.Context) {
+		c.Request = c.Request.WithContext(repository.RequireTenant(c.Request.Context()))
+	}
+	router := controllerRouter(controller.NewAuditLogController(auditLogService), withToken(constants.AdminScope), requireTenant)
+
+	req, _ := http.NewRequest(http.MethodGet, "/audit-log", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.NotContains(t, recorder.statements[0], `"organization" =`)
+}
+
+func TestTenantScopeAssignsAndChecksWrites(t *testing.T) {
+	db, _ := dryRunDB(t)
+	assert.NoError(t, repository.RegisterTenantScope(db))
+	tenant := uuid.New()
+	repo := repository.Bind(repository.NewSystemUserOrganizationRepository(db), repository.WithTenant(context.Background(), tenant))
+
+	membership := &model.SystemUserOrganization{SystemUserId: uuid.New(), UserRole: model.Owner}
+	_, err := repo.Save(nil, nil, membership)
+	assert.NoError(t, err)
+	assert.Equal(t, tenant, membership.OrganizationId)
+
+	_, err = repo.Save(nil, nil, &model.SystemUserOrganization{OrganizationId: uuid.New()})
+	assert.ErrorIs(t, err, repository.ErrCrossTenant)
+}
+
+func TestTenantScopeAllowsRegisteringOrganizations(t *testing.T) {
+	db, _ := dryRunDB(t)
+	assert.NoError(t, repository.RegisterTenantScope(db))
+	repo := repository.NewOrganizationRepository(db)
+
+	// the first organization of a user, and another one
+	_, err := repo.Save(nil, nil, &model.Organization{Name: "Acme"})
+	assert.NoError(t, err)
+	_, err = repository.Bind(repo, repository.WithTenant(context.Background(), uuid.New())).Save(nil, nil, &model.Organization{Name: "Other"})
+	assert.NoError(t, err)
+
+	users := service.NewSystemUserCrudService(repository.NewSystemUserRepository(db), repo,
+		repository.NewSystemUserOrganizationRepository(db), validator.New())
+	_, err = users.RegisterOrganization(&model.Organization{Name: "Acme"})
+	assert.NoError(t, err)
+	_, err = users.RegisterOrganizationForUser(nil, &model.Organization{Name: "Acme"}, uuid.New())
+	assert.NoError(t, err)
+}
+
+func TestTenantMiddlewareLetsUsersWithoutMembershipThrough(t *testing.T) {
+	db, _ := dryRunDB(t)
+	assert.NoError(t, repository.RegisterTenantScope(db))
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/org", withToken(""), router.TenantMiddleware(repository.NewSystemUserOrganizationRepository(db)), func(c *gin.Context) {
+		_, hasTenant := repository.TenantFromContext(c.Request.Context())
+		assert.False(t, hasTenant)
+		_, err := repository.NewSystemUserOrganizationRepository(db).FindByIds(nil, nil, []uuid.UUID{uuid.New()})
+		assert.NoError(t, err)
+		_, err = repository.Bind(repository.NewSystemUserOrganizationRepository(db), c.Request.Context()).FindByIds(nil, nil, []uuid.UUID{uuid.New()})
+		assert.ErrorIs(t, err, repository.ErrTenantRequired)
+		c.Status(http.StatusOK)
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/org", nil)
+	resp := httptest.NewRecorder()
+	engine.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	req, _ = http.NewRequest(http.MethodGet, "/org", nil)
+	req.Header.Set(constants.OrganizationHeader, uuid.NewString())
+	resp = httptest.NewRecorder()
+	engine.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+}
@@ -0,0 +1,36 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/roksky/bootstrap-api/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextSearchRanksPrefixMatches(t *testing.T) {
+	db, recorder := dryRunDB(t)
+	repo := repository.NewOrganizationRepository(db)
+
+	search := &repository.OrganizationSearch{}
+	search.Q = "Acme, in"
+	_, err := repo.Search(nil, search)
+
+	assert.NoError(t, err)
+	sql := recorder.last()
+	assert.Contains(t, sql, `"organizations"."search_vector" @@ to_tsquery('simple', 'acme:* & in:*')`)
+	assert.Contains(t, sql, `ORDER BY ts_rank("organizations"."search_vector", to_tsquery('simple', 'acme:* & in:*')) DESC`)
+}
+
+func TestTextSearchKeepsRequestedOrder(t *testing.T) {
+	db, recorder := dryRunDB(t)
+	repo := repository.NewOrganizationRepository(db)
+
+	search := &repository.OrganizationSearch{}
+	search.Q = "acme"
+	search.OrderBy = "name"
+	_, err := repo.Search(nil, search)
+
+	assert.NoError(t, err)
+	assert.NotContains(t, recorder.last(), "ts_rank")
+	assert.Contains(t, recorder.last(), `ORDER BY "organizations"."name"`)
+}
@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/roksky/bootstrap-api/model"
+	"github.com/roksky/bootstrap-api/repository"
+	"github.com/roksky/bootstrap-api/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactionCommits(t *testing.T) {
+	db, conn := fakeDB(t, 1)
+	repo := repository.NewOrganizationRepository(db)
+
+	err := repository.WithTransaction(context.Background(), db, func(ctx context.Context) error {
+		_, err := repository.Bind(repo, ctx).Save(nil, nil, &model.Organization{Name: "Acme"})
+		return err
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "BEGIN", conn.events[0])
+	assert.NotEmpty(t, conn.statement(`INSERT INTO "organizations"`))
+	assert.Equal(t, "COMMIT", conn.events[len(conn.events)-1])
+	assert.NotContains(t, conn.events, "ROLLBACK")
+}
+
+func TestTransactionRollsBackOnError(t *testing.T) {
+	db, conn := fakeDB(t, 1)
+	failure := errors.New("failure")
+
+	err := repository.WithTransaction(context.Background(), db, func(ctx context.Context) error {
+		if err := repository.Bind(repository.NewOrganizationRepository(db), ctx).Delete(nil, nil, uuid.New()); err != nil {
+			return err
+		}
+		return failure
+	})
+
+	assert.ErrorIs(t, err, failure)
+	assert.NotEmpty(t, conn.statement(`UPDATE "organizations" SET "date_deleted"=$1`))
+	assert.Equal(t, "ROLLBACK", conn.events[len(conn.events)-1])
+	assert.NotContains(t, conn.events, "COMMIT")
+}
+
+func TestTransactionRollsBackOnPanic(t *testing.T) {
+	db, conn := fakeDB(t, 1)
+
+	assert.PanicsWithValue(t, "failure", func() {
+		_ = repository.WithTransaction(context.Background(), db, func(ctx context.Context) error {
+			panic("failure")
+		})
+	})
+
+	assert.Equal(t, []string{"BEGIN", "ROLLBACK"}, conn.events)
+}
+
+func TestNestedTransactionRollsBackToSavepoint(t *testing.T) {
+	db, conn := fakeDB(t, 1)
+	failure := errors.New("failure")
+
+	var nestedErr error
+	err := repository.WithTransaction(context.Background(), db, func(ctx context.Context) error {
+		nestedErr = repository.WithTransaction(ctx, db, func(ctx context.Context) error {
+			assert.NotNil(t, repository.TxFromContext(ctx))
+			return failure
+		})
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.ErrorIs(t, nestedErr, failure)
+	if assert.Len(t, conn.events, 4) {
+		assert.Equal(t, "BEGIN", conn.events[0])
+		assert.Contains(t, conn.events[1], "SAVEPOINT ")
+		assert.Contains(t, conn.events[2], "ROLLBACK TO SAVEPOINT ")
+		assert.Equal(t, "COMMIT", conn.events[3])
+	}
+}
+
+func TestSingleWritesRunInTransaction(t *testing.T) {
+	db, conn := fakeDB(t, 1)
+	organizations := service.NewOrganizationService(repository.NewOrganizationRepository(db), validator.New())
+
+	err := organizations.Delete(nil, uuid.New())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "BEGIN", conn.events[0])
+	assert.Equal(t, "COMMIT", conn.events[len(conn.events)-1])
+
+	// a failed write leaves nothing behind
+	db, conn = fakeDB(t, 0)
+	organizations = service.NewOrganizationService(repository.NewOrganizationRepository(db), validator.New())
+
+	err = organizations.(service.RestoreService[model.Organization, uuid.UUID, repository.OrganizationSearch]).Purge(nil, []uuid.UUID{uuid.New()})
+
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	assert.Equal(t, "BEGIN", conn.events[0])
+	assert.Equal(t, "ROLLBACK", conn.events[len(conn.events)-1])
+}
@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-oauth2/oauth2/v4/models"
+	"github.com/google/uuid"
+	"github.com/roksky/bootstrap-api/apperror"
+	"github.com/roksky/bootstrap-api/constants"
+	"github.com/roksky/bootstrap-api/controller"
+	"github.com/roksky/bootstrap-api/helper"
+	"github.com/roksky/bootstrap-api/repository"
+	"github.com/roksky/bootstrap-api/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergePatch(t *testing.T) {
+	target := map[string]interface{}{
+		"theme":         "dark",
+		"language":      "en",
+		"notifications": map[string]interface{}{"email": true, "sms": true},
+	}
+	patch := map[string]interface{}{
+		"language":      nil,
+		"notifications": map[string]interface{}{"sms": false},
+		"pageSize":      50.0,
+	}
+
+	patched := helper.MergePatch(target, patch)
+
+	assert.Equal(t, map[string]interface{}{
+		"theme":         "dark",
+		"notifications": map[string]interface{}{"email": true, "sms": false},
+		"pageSize":      50.0,
+	}, patched)
+	assert.Equal(t, "en", target["language"], "the target is left unchanged")
+}
+
+func TestPreferencesDefaultToRegisteredDocument(t *testing.T) {
+	db, recorder := dryRunDB(t)
+	preferencesService := service.NewUserPreferencesService(repository.NewUserPreferencesRepository(db)).
+		SetDefaults(map[string]interface{}{"theme": "light"})
+	userId := uuid.New()
+
+	preferences, err := preferencesService.Get(userId)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"theme": "light"}, preferences)
+	assert.Contains(t, recorder.last(), `WHERE "user_preferences"."system_user" = '`+userId.String()+`'`)
+}
+
+func TestPreferencesRejectNonSystemUsers(t *testing.T) {
+	db, recorder := dryRunDB(t)
+	preferencesController := controller.NewUserPreferencesController(service.NewUserPreferencesService(repository.NewUserPreferencesRepository(db)))
+	router := controllerRouter(preferencesController, func(ctx *gin.Context) {
+		ctx.Set(constants.TokenKey, &models.Token{UserID: "client-app"})
+	})
+
+	resp := serve(router, http.MethodGet, "/me/preferences")
+
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	assert.Empty(t, recorder.statements)
+}
+
+// uniqueViolation is the error of a statement violating a unique constraint.
+type uniqueViolation struct{}
+
+func (uniqueViolation) Error() string {
+	return "duplicate key value violates unique constraint"
+}
+
+func (uniqueViolation) SQLState() string {
+	return "23505"
+}
+
+func TestConcurrentFirstPreferencesSaveIsRetried(t *testing.T) {
+	db, conn := fakeDB(t, 1)
+	userId := uuid.New()
+	conn.failing = `INSERT INTO "user_preferences"`
+	conn.failure = uniqueViolation{}
+	conn.results = map[string]fakeRows{
+		// the preferences saved concurrently are only found once the insert failed
+		`SELECT * FROM "user_preferences"`: {
+			columns: []string{"id", "system_user", "preferences", "version"},
+			values:  [][]driver.Value{{uuid.NewString(), userId.String(), `{"theme": "dark"}`, int64(1)}},
+			skip:    1,
+		},
+	}
+	preferencesService := service.NewUserPreferencesService(repository.NewUserPreferencesRepository(db))
+
+	_, err := preferencesService.Patch(userId, map[string]interface{}{"language": "fr"})
+
+	assert.NoError(t, err)
+	assert.Contains(t, conn.events, "ROLLBACK")
+	assert.NotEmpty(t, conn.statement(`UPDATE "user_preferences" SET`))
+
+	// the saves keep conflicting
+	db, conn = fakeDB(t, 1)
+	conn.failing = `INSERT INTO "user_preferences"`
+	conn.failure = uniqueViolation{}
+	preferencesService = service.NewUserPreferencesService(repository.NewUserPreferencesRepository(db))
+
+	_, err = preferencesService.Patch(userId, map[string]interface{}{"language": "fr"})
+
+	assert.ErrorIs(t, err, service.ErrPreferencesConflict)
+	assert.Equal(t, apperror.KindConflict, apperror.From(err).Kind)
+}
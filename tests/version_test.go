@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/roksky/bootstrap-api/controller"
+	"github.com/roksky/bootstrap-api/model"
+	"github.com/roksky/bootstrap-api/repository"
+	"github.com/roksky/bootstrap-api/service"
+	"github.com/roksky/bootstrap-api/types"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestUpdateChecksAndIncrementsVersion(t *testing.T) {
+	db, recorder := dryRunDB(t)
+	repo := repository.NewOrganizationRepository(db)
+
+	item := &model.Organization{Name: "acme"}
+	item.Id = uuid.New()
+	item.Version = 2
+	_, err := repo.Update(nil, nil, item)
+
+	// a dry run affects no rows, so the update is reported as failed
+	assert.Error(t, err)
+	assert.Equal(t, int64(2), item.Version)
+	assert.Contains(t, recorder.statements[0], `"version"=3`)
+	assert.Contains(t, recorder.statements[0], `WHERE "organizations"."version" = 2`)
+}
+
+func TestDeleteIncrementsVersion(t *testing.T) {
+	db, recorder := dryRunDB(t)
+	repo := repository.NewOrganizationRepository(db)
+
+	err := repo.Delete(nil, nil, uuid.New())
+
+	assert.NoError(t, err)
+	assert.Contains(t, recorder.statements[0], `"version"="organizations"."version" + 1`)
+	assert.Contains(t, recorder.statements[1], `SET "date_deleted"=`)
+}
+
+func TestDeleteKeepsTheActorOfTheDeprecatedPageRequest(t *testing.T) {
+	db, recorder := dryRunDB(t)
+	search := &repository.OrganizationSearch{}
+	search.SetActor("alice")
+
+	err := repository.NewOrganizationRepository(db).Delete(nil, search, uuid.New())
+
+	assert.NoError(t, err)
+	assert.Contains(t, recorder.statements[0], `"deleted_by"='alice'`)
+}
+
+// organizationHistory serves a version of an organization whose current version is current, and
+// keeps the organization it is replaced with.
+type organizationHistory struct {
+	*repository.OrganizationRepository
+	current  *model.Organization
+	snapshot string
+	replaced *model.Organization
+}
+
+func (r *organizationHistory) WithContext(context.Context) repository.BaseRepository[model.Organization, uuid.UUID, repository.OrganizationSearch] {
+	return r
+}
+
+func (r *organizationHistory) Version(_ *gorm.DB, itemId uuid.UUID, version int64) (*model.EntityVersion, error) {
+	return &model.EntityVersion{EntityId: itemId.String(), Version: version, Snapshot: types.JSON(r.snapshot)}, nil
+}
+
+func (r *organizationHistory) FindById(*gorm.DB, *repository.OrganizationSearch, uuid.UUID) (*model.Organization, error) {
+	return r.current, nil
+}
+
+func (r *organizationHistory) Replace(_ *gorm.DB, _ *repository.OrganizationSearch, item *model.Organization) (*model.Organization, error) {
+	r.replaced = item
+	return item, nil
+}
+
+func TestRevertRestoresZeroValues(t *testing.T) {
+	db, _ := dryRunDB(t)
+	id := uuid.New()
+	history := &organizationHistory{
+		OrganizationRepository: repository.NewOrganizationRepository(db).(*repository.OrganizationRepository),
+		current:                &model.Organization{IdentifiedModel: model.IdentifiedModel{Id: id, Version: 5}, Name: "Acme"},
+		snapshot:               `{"id": "` + uuid.NewString() + `", "name": "", "version": 2, "dateDeleted": "2024-01-01T00:00:00Z", "createdBy": "mallory"}`,
+	}
+	organizations := service.NewOrganizationService(history, validator.New()).(service.HistoryService[model.Organization, uuid.UUID, repository.OrganizationSearch])
+
+	_, err := organizations.Revert(nil, id, 2)
+
+	assert.NoError(t, err)
+	if assert.NotNil(t, history.replaced) {
+		assert.Equal(t, id, history.replaced.Id)
+		assert.Empty(t, history.replaced.Name)
+		assert.Equal(t, int64(5), history.replaced.Version)
+		assert.False(t, history.replaced.DateDeleted.Valid)
+		assert.Empty(t, history.replaced.CreatedBy)
+	}
+}
+
+func TestRevertRouteChecksIfMatchAndSetsETag(t *testing.T) {
+	db, _ := dryRunDB(t)
+	id := uuid.New()
+	history := &organizationHistory{
+		OrganizationRepository: repository.NewOrganizationRepository(db).(*repository.OrganizationRepository),
+		current:                &model.Organization{IdentifiedModel: model.IdentifiedModel{Id: id, Version: 5}, Name: "Acme"},
+		snapshot:               `{"name": "Former"}`,
+	}
+	router := controllerRouter(controller.NewOrganizationController(service.NewOrganizationService(history, validator.New())), withToken(""))
+	path := "/org/" + id.String() + "/versions/2/revert"
+
+	resp := conditionalRequest(router, http.MethodPost, path, "If-Match", `"v4"`, "")
+	assert.Equal(t, http.StatusPreconditionFailed, resp.Code)
+	assert.Nil(t, history.replaced)
+
+	resp = conditionalRequest(router, http.MethodPost, path, "If-Match", `"v5"`, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, `"v5"`, resp.Header().Get("ETag"))
+	if assert.NotNil(t, history.replaced) {
+		assert.Equal(t, "Former", history.replaced.Name)
+		assert.Equal(t, int64(5), history.replaced.Version)
+	}
+}
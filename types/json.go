@@ -0,0 +1,44 @@
+package types
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// JSON is a raw JSON document stored in a jsonb column.
+type JSON []byte
+
+func (j JSON) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return j, nil
+}
+
+func (j *JSON) UnmarshalJSON(data []byte) error {
+	*j = append((*j)[0:0], data...)
+	return nil
+}
+
+func (j JSON) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+	return string(j), nil
+}
+
+func (j *JSON) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*j = nil
+		return nil
+	case []byte:
+		*j = append((*j)[0:0], v...)
+		return nil
+	case string:
+		*j = JSON(v)
+		return nil
+	default:
+		return fmt.Errorf("can not convert %v to JSON", value)
+	}
+}